@@ -9,19 +9,42 @@ import (
 type TaskManagerAdapter interface {
 	Initialize(Settings) error
 	FetchTasks() ([]Task, error)
+	FetchProjects() ([]Project, error)
+	FetchCompletedTasks(since time.Time, until time.Time) ([]Task, error)
 	UpdateTasks(*[]TaskAction) error
+	EditTasks(*[]TaskEdit) error
+	CreateTasks(*[]TaskDraft) error
+	ArchiveProjects(*[]Project) error
 }
 
 type ArchiverAdapter interface {
-	Initialize(Settings) error
+	Initialize(ArchiverConfig) error
+	Archive(title string, actions *[]TaskAction) error
+	WriteDailyNote(note *DailyNote) error
+	ArchiveArticles(title string, articles *[]Article) error
+}
+
+// ProjectCreator is implemented by task managers that can create projects,
+// such as the ones imported tasks belong to.
+type ProjectCreator interface {
+	CreateProjects(names []string) ([]Project, error)
+}
+
+// LabelCreator is implemented by task managers that keep a list of labels
+// tasks can only refer to once created.
+type LabelCreator interface {
+	FetchLabels() ([]string, error)
+	CreateLabels(names []string) error
 }
 
 type Priority int8
 type Status int8
 type Action int8
+type Energy int8
 
 const (
-	// Priority
+	// Priority, from the most urgent; task managers convert their own scale
+	// (see todoist.toPriority)
 	PriorityCritical Priority = 1
 	PriorityHigh     Priority = 2
 	PriorityMedium   Priority = 3
@@ -33,6 +56,7 @@ const (
 	StatusDeleted   Status = 3
 	StatusNext      Status = 4
 	StatusSomeday   Status = 5
+	StatusWaiting   Status = 6
 
 	// Actions
 	ActionIgnore     Action = 0
@@ -40,28 +64,119 @@ const (
 	ActionDelete     Action = 2
 	ActionRevalidate Action = 3
 	ActionDefer      Action = 4
+
+	// Energy
+	EnergyUnknown Energy = 0
+	EnergyLow     Energy = 1
+	EnergyMedium  Energy = 2
+	EnergyHigh    Energy = 3
 )
 
 type Task struct {
-	ID          string    `json:"id"`
-	Project     string    `json:"project"`
-	Content     string    `json:"content"`
-	CreatedDate time.Time `json:"created_at"`
-	UpdatedDate time.Time `json:"modified_at"`
-	Tags        []string  `json:"tags"`
-	Status      Status    `json:"status"`
-	Priority    Priority  `json:"priority"`
-	TaskManger  string    `json:"taskmanager"`
+	ID          string         `json:"id"`
+	ProjectID   string         `json:"project_id"`
+	Project     string         `json:"project"`
+	Inbox       bool           `json:"inbox,omitempty"`
+	Section     string         `json:"section,omitempty"`
+	Content     string         `json:"content"`
+	Description string         `json:"description,omitempty"`
+	Notes       []string       `json:"notes,omitempty"`
+	CreatedDate time.Time      `json:"created_at"`
+	UpdatedDate time.Time      `json:"modified_at"`
+	CompletedAt *time.Time     `json:"completed_at,omitempty"`
+	DueDate     *time.Time     `json:"due_at,omitempty"`
+	Duration    *time.Duration `json:"duration,omitempty"`
+	Tags        []string       `json:"tags"`
+	Status      Status         `json:"status"`
+	Priority    Priority       `json:"priority"`
+	Energy      Energy         `json:"energy,omitempty"`
+	Waiting     *WaitingInfo   `json:"waiting,omitempty"`
+	TaskManger  string         `json:"taskmanager"`
 }
 
 type FilterRequest struct {
-	Project       *string
-	Status        *Status
-	AfterTimeSpan *TimeSpan
-	Tags          *[]string
+	Projects         *[]string // glob patterns, a task matches if any of them match
+	Statuses         *[]Status
+	ExcludedStatuses *[]Status
+	MinPriority      *Priority // lowest value, so the most urgent priority, allowed
+	MaxPriority      *Priority // highest value, so the least urgent priority, allowed
+	AnyTags          *[]string
+	AllTags          *[]string
+	NoTags           *[]string
+	CreatedAfter     *TimeSpan
+	CreatedBefore    *TimeSpan
+	UpdatedAfter     *TimeSpan
+	UpdatedBefore    *TimeSpan
+	UpdatedSince     *time.Time
+	UpdatedUntil     *time.Time
+	MaxDuration      *time.Duration // tasks without an estimate always fit
+	MaxEnergy        *Energy        // tasks without an energy level always fit
+	Query            TaskMatcher
+	Location         *time.Location // timezone days start in, the local one when nil
+}
+
+// TaskMatcher is implemented by parsed filter expressions (see the query
+// package), allowing them to be carried by a FilterRequest.
+type TaskMatcher interface {
+	Matches(task *Task, now time.Time) bool
 }
 
 type TaskAction struct {
 	Task   *Task
 	Action Action
 }
+
+type Project struct {
+	ID          string     `json:"id"`
+	ParentID    string     `json:"parent_id,omitempty"`
+	Name        string     `json:"name"`
+	Inbox       bool       `json:"inbox,omitempty"`
+	Archived    bool       `json:"archived,omitempty"`
+	Sections    []string   `json:"sections,omitempty"`
+	CreatedDate *time.Time `json:"created_at,omitempty"`
+}
+
+// TaskEdit describes changes to a single task. Empty fields are left
+// untouched; when Delete is set all other changes are ignored.
+type TaskEdit struct {
+	Task        *Task
+	Content     *string
+	ProjectID   *string
+	Status      *Status // moves the task between active, next, someday and waiting
+	Priority    *Priority
+	AddTags     []string
+	RemoveTags  []string
+	Due         *string // natural language, interpreted by the task manager
+	Description *string
+	Subtasks    []string // contents of subtasks to create under the task
+	Note        *string
+	Complete    bool
+	Delete      bool
+}
+
+// TaskDraft is a task to be created. Without a ProjectID it goes to the
+// inbox.
+type TaskDraft struct {
+	Content     string
+	Description string
+	ProjectID   string
+	Tags        []string
+	Priority    Priority // zero leaves the task manager's default
+	Due         *string  // natural language, interpreted by the task manager
+}
+
+func (e *TaskEdit) IsEmpty() bool {
+	return e.Content == nil && e.ProjectID == nil && e.Status == nil && e.Priority == nil && len(e.AddTags) == 0 &&
+		len(e.RemoveTags) == 0 && e.Due == nil && e.Description == nil && len(e.Subtasks) == 0 && e.Note == nil &&
+		!e.Complete && !e.Delete
+}
+
+// DailyNote is the day's plan as written by an archiver.
+type DailyNote struct {
+	Date      time.Time
+	Planned   []Task
+	Due       []Task
+	Overdue   []Task
+	Next      []Task
+	Completed []Task // completed the day before
+}
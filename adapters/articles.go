@@ -0,0 +1,53 @@
+package adapters
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const maxArticleTitleWords = 3
+
+var (
+	markdownLinkRegex = regexp.MustCompile(`^\[([^\]]*)\]\((https?://[^)\s]+)\)$`)
+	urlRegex          = regexp.MustCompile(`https?://\S+`)
+)
+
+// Article is a read-later task.
+type Article struct {
+	Task    *Task  `json:"task"`
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Summary string `json:"summary,omitempty"`
+	Read    bool   `json:"read,omitempty"`
+}
+
+// ParseArticle tells whether a task is a read-later item: its content is a
+// link, a URL with at most a few words around it, or it carries label.
+func ParseArticle(task *Task, label string) (*Article, bool) {
+	content := strings.TrimSpace(task.Content)
+	if match := markdownLinkRegex.FindStringSubmatch(content); match != nil {
+		title := match[1]
+		if title == "" {
+			title = match[2]
+		}
+		return &Article{Task: task, Title: title, URL: match[2]}, true
+	}
+
+	url := urlRegex.FindString(content)
+	if url == "" {
+		url = urlRegex.FindString(task.Description)
+	}
+	rest := strings.TrimSpace(strings.Replace(content, url, "", 1))
+	labelled := label != "" && slices.Contains(task.Tags, label)
+	mostlyURL := url != "" && strings.Contains(content, url) && len(strings.Fields(rest)) <= maxArticleTitleWords
+	if !labelled && !mostlyURL {
+		return nil, false
+	}
+
+	title := strings.Join(strings.Fields(rest), " ")
+	if title == "" {
+		title = url
+	}
+	return &Article{Task: task, Title: title, URL: url}, true
+}
@@ -1,7 +1,6 @@
 package adapters
 
 import (
-	"fmt"
 	"gopkg.in/yaml.v2"
 	"os"
 	"path/filepath"
@@ -20,36 +19,107 @@ type TodoistConfig struct {
 	ClientID     *string   `yaml:"client_id"`
 	ClientSecret *string   `yaml:"client_secret"`
 	Scopes       *[]string `yaml:"scopes"`
+	WaitingLabel string    `yaml:"waiting_label"` // defaults to "waiting_for"
+}
+
+type TaskwarriorConfig struct {
+	Command string `yaml:"command"` // defaults to "task"
+}
+
+// ViewConfig is a named, shareable slice of tasks: a filter expression
+// together with how the matching tasks are sorted and displayed.
+type ViewConfig struct {
+	Query   string   `yaml:"query"`
+	Sort    []string `yaml:"sort"`
+	Columns []string `yaml:"columns"`
+}
+
+type ArchiverType string
+
+const (
+	ArchiverTypeMarkdown ArchiverType = "markdown"
+	ArchiverTypeObsidian ArchiverType = "obsidian"
+)
+
+type ArchiveMode string
+
+const (
+	ArchiveModeAppend ArchiveMode = "append"
+	ArchiveModeNew    ArchiveMode = "new"
+)
+
+type ArchiverConfig struct {
+	Type       ArchiverType `yaml:"type"`
+	Path       string       `yaml:"path"`
+	Filename   string       `yaml:"filename"`    // may contain {date}, defaults to "gitd-archive.md"
+	DateFormat string       `yaml:"date_format"` // Go layout for {date}, defaults to "2006-01-02"
+	Mode       ArchiveMode  `yaml:"mode"`
+	DailyNote  string       `yaml:"daily_note"` // may contain {date} (2006-01-02), defaults to "{date}.md"
+}
+
+type ReviewStepConfig struct {
+	Name     string   `yaml:"name"`
+	Prompt   string   `yaml:"prompt"`
+	Filter   string   `yaml:"filter"`
+	Actions  []string `yaml:"actions"`
+	Archiver string   `yaml:"archiver"`
+}
+
+type ReviewConfig struct {
+	Steps []ReviewStepConfig `yaml:"steps"`
+}
+
+// ArticlesConfig describes how read-later items are recognised and where
+// they are documented.
+type ArticlesConfig struct {
+	Label       string `yaml:"label"`        // defaults to "read_later"
+	Archiver    string `yaml:"archiver"`     // documents read articles
+	ReadingList string `yaml:"reading_list"` // keeps articles moved out of the task manager
 }
 
 type Settings struct {
-	Todoist TodoistConfig `yaml:"todoist"`
+	Todoist     TodoistConfig             `yaml:"todoist"`
+	Taskwarrior TaskwarriorConfig         `yaml:"taskwarrior"`
+	Views       map[string]ViewConfig     `yaml:"views"`
+	Archivers   map[string]ArchiverConfig `yaml:"archivers"`
+	Reviews     map[string]ReviewConfig   `yaml:"reviews"`
+	Articles    ArticlesConfig            `yaml:"articles"`
 }
 
-func GetConfigFilePath() string {
+func GetConfigFilePath() (string, error) {
+	return gitdPath("config.yaml")
+}
+
+// GetStateFilePath returns the path of a file gitd keeps its own state in,
+// such as the progress of an interrupted review.
+func GetStateFilePath(name string) (string, error) {
+	return gitdPath(name)
+}
+
+// gitdPath returns the path of name in ~/.gitd.
+func gitdPath(name string) (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
-		fmt.Println("Error getting user's home directory:", err)
-		os.Exit(1)
+		return "", Errorf(ErrorConfig, "error getting user's home directory: %w", err)
 	}
-
-	return filepath.Join(home, ".gitd", "config.yaml")
+	return filepath.Join(home, ".gitd", name), nil
 }
 
-func GetSettings() Settings {
-	configPath := GetConfigFilePath()
+func GetSettings() (Settings, error) {
+	configPath, err := GetConfigFilePath()
+	if err != nil {
+		return Settings{}, err
+	}
 
 	data, err := os.ReadFile(configPath)
 	if err != nil {
-		fmt.Println("Error reading config file:", err)
-		os.Exit(1)
+		return Settings{}, Errorf(ErrorConfig, "error reading config file: %w", err)
 	}
 
 	var settings Settings
 	if err := yaml.Unmarshal(data, &settings); err != nil {
-		fmt.Println("Error unmarshalling YAML:", err)
-		os.Exit(1)
+		return Settings{}, Errorf(ErrorConfig, "error unmarshalling YAML in %s: %w", configPath, err)
 	}
 
-	return settings
+	return settings, nil
 }
@@ -0,0 +1,26 @@
+package adapters
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestGetStateFilePath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path, err := GetStateFilePath("journal.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, ".gitd", "journal.jsonl"); path != want {
+		t.Errorf("got %s, want %s", path, want)
+	}
+
+	t.Setenv("HOME", "")
+	if _, err := GetStateFilePath("journal.jsonl"); KindOf(err) != ErrorConfig {
+		t.Errorf("got error %v, want a config error", err)
+	}
+	if _, err := GetSettings(); KindOf(err) != ErrorConfig {
+		t.Errorf("got error %v, want a config error", err)
+	}
+}
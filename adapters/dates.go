@@ -0,0 +1,198 @@
+package adapters
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TimezoneProvider is implemented by task managers that know the user's
+// timezone, so relative dates resolve the way the user sees them.
+type TimezoneProvider interface {
+	Location() (*time.Location, error)
+}
+
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+	"sun":       time.Sunday,
+	"mon":       time.Monday,
+	"tue":       time.Tuesday,
+	"wed":       time.Wednesday,
+	"thu":       time.Thursday,
+	"fri":       time.Friday,
+	"sat":       time.Saturday,
+}
+
+// ParseDate resolves absolute dates ("2026-01-01", RFC 3339) and natural
+// language ones relative to now, in now's location:
+//
+//	now, today, yesterday, tomorrow
+//	friday, last friday, next friday, this friday
+//	last week, this month, next quarter (the start of that period)
+//	start of quarter, end of last month, beginning of year
+//	3 days ago, in 2 weeks (see NewTimeSpan for span syntax)
+//
+// A bare weekday means today or its last occurrence, as these dates mostly
+// bound past activity; see ParseDueDate. Weeks start on Monday.
+func ParseDate(input string, now time.Time) (time.Time, error) {
+	return parseDate(input, now, false)
+}
+
+// ParseDueDate is ParseDate for due dates, where a bare weekday means today
+// or its next occurrence.
+func ParseDueDate(input string, now time.Time) (time.Time, error) {
+	return parseDate(input, now, true)
+}
+
+func parseDate(input string, now time.Time, upcoming bool) (time.Time, error) {
+	trimmed := strings.TrimSpace(input)
+	for _, layout := range dateLayouts {
+		if date, err := time.ParseInLocation(layout, trimmed, now.Location()); err == nil {
+			return date, nil
+		}
+	}
+
+	normalized := strings.Join(strings.Fields(strings.ToLower(trimmed)), " ")
+	today := startOfDay(now)
+
+	switch normalized {
+	case "now":
+		return now, nil
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	}
+
+	if strings.HasSuffix(normalized, " ago") {
+		if span, err := NewTimeSpan(normalized); err == nil {
+			return span.ModifyDate(now, false), nil
+		}
+	}
+	if rest, ok := strings.CutPrefix(normalized, "in "); ok {
+		if span, err := NewTimeSpan(rest); err == nil {
+			return span.ModifyDate(now, true), nil
+		}
+	}
+
+	for _, prefix := range []string{"start of ", "beginning of ", "end of "} {
+		if rest, ok := strings.CutPrefix(normalized, prefix); ok {
+			start, end, err := resolvePeriod(rest, today)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid date %q: %w", input, err)
+			}
+			if prefix == "end of " {
+				return end.Add(-time.Nanosecond), nil
+			}
+			return start, nil
+		}
+	}
+
+	if date, ok := resolveWeekday(normalized, today, upcoming); ok {
+		return date, nil
+	}
+	if start, _, err := resolvePeriod(normalized, today); err == nil && strings.Contains(normalized, " ") {
+		return start, nil
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized date: %s", input)
+}
+
+func startOfDay(date time.Time) time.Time {
+	year, month, day := date.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, date.Location())
+}
+
+// resolveWeekday resolves "friday", "last friday", "next friday" and "this
+// friday". A bare weekday is the upcoming one, or the past one otherwise.
+func resolveWeekday(input string, today time.Time, upcoming bool) (time.Time, bool) {
+	modifier, name, found := strings.Cut(input, " ")
+	if !found {
+		modifier, name = "", input
+	}
+	weekday, ok := weekdays[name]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	offset := int(weekday - today.Weekday())
+	switch modifier {
+	case "":
+		if upcoming && offset < 0 {
+			offset += 7
+		} else if !upcoming && offset > 0 {
+			offset -= 7
+		}
+	case "next":
+		if offset <= 0 {
+			offset += 7
+		}
+	case "last":
+		if offset >= 0 {
+			offset -= 7
+		}
+	case "this":
+		// the given weekday within the current Monday-based week
+		offset = (int(weekday)+6)%7 - (int(today.Weekday())+6)%7
+	default:
+		return time.Time{}, false
+	}
+	return today.AddDate(0, 0, offset), true
+}
+
+// resolvePeriod returns the bounds [start, end) of periods such as "week",
+// "last month" or "next quarter" around today.
+func resolvePeriod(input string, today time.Time) (time.Time, time.Time, error) {
+	modifier, unit, found := strings.Cut(input, " ")
+	if !found {
+		modifier, unit = "this", input
+	}
+	shift := 0
+	switch modifier {
+	case "this", "the":
+	case "last", "previous":
+		shift = -1
+	case "next":
+		shift = 1
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown period modifier: %s", modifier)
+	}
+
+	year, month, _ := today.Date()
+	location := today.Location()
+	switch unit {
+	case "day":
+		start := today.AddDate(0, 0, shift)
+		return start, start.AddDate(0, 0, 1), nil
+	case "week":
+		start := today.AddDate(0, 0, -((int(today.Weekday())+6)%7)+7*shift)
+		return start, start.AddDate(0, 0, 7), nil
+	case "month":
+		start := time.Date(year, month+time.Month(shift), 1, 0, 0, 0, 0, location)
+		return start, start.AddDate(0, 1, 0), nil
+	case "quarter":
+		quarterMonth := month - (month-1)%3
+		start := time.Date(year, quarterMonth+time.Month(3*shift), 1, 0, 0, 0, 0, location)
+		return start, start.AddDate(0, 3, 0), nil
+	case "year":
+		start := time.Date(year+shift, 1, 1, 0, 0, 0, 0, location)
+		return start, start.AddDate(1, 0, 0), nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown period: %s", unit)
+	}
+}
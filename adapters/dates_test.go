@@ -0,0 +1,73 @@
+package adapters
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	location := time.FixedZone("UTC+2", 2*60*60)
+	// Wednesday 2026-10-14, 15:30
+	now := time.Date(2026, 10, 14, 15, 30, 0, 0, location)
+	day := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, location)
+	}
+
+	tests := []struct {
+		input string
+		want  time.Time
+		due   time.Time // for ParseDueDate, when it differs
+	}{
+		{"2026-01-01", day(2026, 1, 1), time.Time{}},
+		{"2026-01-01 08:15", time.Date(2026, 1, 1, 8, 15, 0, 0, location), time.Time{}},
+		{"2026-01-01T08:15:00Z", time.Date(2026, 1, 1, 8, 15, 0, 0, time.UTC), time.Time{}},
+		{"now", now, time.Time{}},
+		{"today", day(2026, 10, 14), time.Time{}},
+		{"Yesterday", day(2026, 10, 13), time.Time{}},
+		{"tomorrow", day(2026, 10, 15), time.Time{}},
+		{"friday", day(2026, 10, 9), day(2026, 10, 16)},
+		{"monday", day(2026, 10, 12), day(2026, 10, 19)},
+		{"wednesday", day(2026, 10, 14), time.Time{}},
+		{"fri", day(2026, 10, 9), day(2026, 10, 16)},
+		{"last friday", day(2026, 10, 9), time.Time{}},
+		{"last wednesday", day(2026, 10, 7), time.Time{}},
+		{"next monday", day(2026, 10, 19), time.Time{}},
+		{"next wednesday", day(2026, 10, 21), time.Time{}},
+		{"this sunday", day(2026, 10, 18), time.Time{}},
+		{"this monday", day(2026, 10, 12), time.Time{}},
+		{"start of quarter", day(2026, 10, 1), time.Time{}},
+		{"start of last quarter", day(2026, 7, 1), time.Time{}},
+		{"start of month", day(2026, 10, 1), time.Time{}},
+		{"beginning of year", day(2026, 1, 1), time.Time{}},
+		{"end of last month", day(2026, 10, 1).Add(-time.Nanosecond), time.Time{}},
+		{"last week", day(2026, 10, 5), time.Time{}},
+		{"next month", day(2026, 11, 1), time.Time{}},
+		{"3 days ago", now.AddDate(0, 0, -3), time.Time{}},
+		{"in 2 weeks", now.AddDate(0, 0, 14), time.Time{}},
+	}
+	for _, tt := range tests {
+		got, err := ParseDate(tt.input, now)
+		if err != nil {
+			t.Errorf("ParseDate(%q): %v", tt.input, err)
+		} else if !got.Equal(tt.want) {
+			t.Errorf("ParseDate(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+
+		due := tt.due
+		if due.IsZero() {
+			due = tt.want
+		}
+		if got, err := ParseDueDate(tt.input, now); err != nil || !got.Equal(due) {
+			t.Errorf("ParseDueDate(%q) = %s, %v, want %s", tt.input, got, err, due)
+		}
+	}
+}
+
+func TestParseDateInvalid(t *testing.T) {
+	now := time.Date(2026, 10, 14, 15, 30, 0, 0, time.UTC)
+	for _, input := range []string{"", "someday", "friyay", "start of decade", "last fortnight", "2026-13-01", "month"} {
+		if got, err := ParseDate(input, now); err == nil {
+			t.Errorf("ParseDate(%q) = %s, want an error", input, got)
+		}
+	}
+}
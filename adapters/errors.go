@@ -0,0 +1,103 @@
+package adapters
+
+import (
+	"errors"
+	"fmt"
+)
+
+type ErrorKind int8
+
+const (
+	ErrorUnknown ErrorKind = iota
+	ErrorValidation
+	ErrorConfig
+	ErrorAuth
+	ErrorNetwork
+	ErrorAPI
+	ErrorAborted
+)
+
+var errorKindNames = map[ErrorKind]string{
+	ErrorUnknown:    "unknown",
+	ErrorValidation: "validation",
+	ErrorConfig:     "config",
+	ErrorAuth:       "auth",
+	ErrorNetwork:    "network",
+	ErrorAPI:        "api",
+	ErrorAborted:    "aborted",
+}
+
+// exitCodes are documented in the README; do not renumber them.
+var exitCodes = map[ErrorKind]int{
+	ErrorUnknown:    1,
+	ErrorValidation: 2,
+	ErrorConfig:     3,
+	ErrorAuth:       4,
+	ErrorNetwork:    5,
+	ErrorAPI:        6,
+	ErrorAborted:    7,
+}
+
+// ErrAborted is returned when the user quits or declines a confirmation.
+var ErrAborted = &Error{Kind: ErrorAborted, Err: errors.New("aborted, no changes made")}
+
+// Error is an error of a known kind, which decides gitd's exit code.
+type Error struct {
+	Kind ErrorKind
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (k ErrorKind) String() string {
+	return errorKindNames[k]
+}
+
+// NewError gives err a kind, unless it already has one.
+func NewError(kind ErrorKind, err error) error {
+	if err == nil || KindOf(err) != ErrorUnknown {
+		return err
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+func Errorf(kind ErrorKind, format string, args ...any) error {
+	return NewError(kind, fmt.Errorf(format, args...))
+}
+
+func KindOf(err error) ErrorKind {
+	var typed *Error
+	if errors.As(err, &typed) {
+		return typed.Kind
+	}
+	return ErrorUnknown
+}
+
+// ExitCode is the process exit code for err, 0 if there is none.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	return exitCodes[KindOf(err)]
+}
+
+// PartialError is returned when a batch of changes was only partly applied.
+// Failed names the tasks whose changes were not applied.
+type PartialError struct {
+	Err    error
+	Failed []string
+}
+
+func (e *PartialError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PartialError) Unwrap() error {
+	return e.Err
+}
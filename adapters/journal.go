@@ -0,0 +1,66 @@
+package adapters
+
+import (
+	"slices"
+	"time"
+)
+
+// Undoer is implemented by task managers that can reverse applied actions.
+type Undoer interface {
+	UndoActions(run *JournalRun) error
+}
+
+// JournalRun records one batch of applied actions with the tasks as they
+// were before, so that it can be undone.
+type JournalRun struct {
+	ID          string         `json:"id"`
+	TaskManager string         `json:"taskmanager"`
+	AppliedAt   time.Time      `json:"applied_at"`
+	Entries     []JournalEntry `json:"entries"`
+	Undone      bool           `json:"undone,omitempty"`
+	Pending     bool           `json:"pending,omitempty"` // journaled, but not known to be applied yet
+}
+
+type JournalEntry struct {
+	Action Action `json:"action"`
+	Task   Task   `json:"task"` // snapshot taken before the action
+	Failed bool   `json:"failed,omitempty"`
+}
+
+// NewJournalRun snapshots the tasks the actions change, leaving ignored
+// ones out.
+func NewJournalRun(actions *[]TaskAction) *JournalRun {
+	now := time.Now()
+	run := &JournalRun{
+		ID:        now.Format("20060102-150405.000"),
+		AppliedAt: now,
+	}
+	for _, action := range *actions {
+		if action.Action == ActionIgnore || action.Task == nil {
+			continue
+		}
+		run.TaskManager = action.Task.TaskManger
+		run.Entries = append(run.Entries, JournalEntry{Action: action.Action, Task: *action.Task})
+	}
+	return run
+}
+
+// MarkFailed flags the entries of the given tasks as not applied.
+func (r *JournalRun) MarkFailed(taskIDs []string) {
+	for i := range r.Entries {
+		if slices.Contains(taskIDs, r.Entries[i].Task.ID) {
+			r.Entries[i].Failed = true
+		}
+	}
+}
+
+// Applied returns the entries that were not marked as failed.
+func (r *JournalRun) Applied() []JournalEntry {
+	var applied []JournalEntry
+	for _, entry := range r.Entries {
+		if !entry.Failed {
+			applied = append(applied, entry)
+		}
+	}
+	return applied
+}
@@ -0,0 +1,66 @@
+package adapters
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Planner is implemented by task managers that can describe the commands
+// they would send for a set of actions, and send them later.
+type Planner interface {
+	PlanTasks(actions *[]TaskAction) (*Plan, error)
+	ApplyPlan(plan *Plan) error
+}
+
+// Plan is a reviewable set of changes, made now and applied later.
+type Plan struct {
+	TaskManager string     `json:"taskmanager"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Steps       []PlanStep `json:"steps"`
+}
+
+// PlanStep holds the commands for one task, along with the state the task
+// was in when the plan was made.
+type PlanStep struct {
+	TaskID      string            `json:"task_id"`
+	Content     string            `json:"content"`
+	Project     string            `json:"project"`
+	Action      Action            `json:"action"`
+	UpdatedDate time.Time         `json:"updated_at"`
+	Fingerprint string            `json:"fingerprint"`
+	Commands    []json.RawMessage `json:"commands"`
+}
+
+// NewPlanStep records task as it is now, so that changes made to it before
+// the plan is applied can be detected.
+func NewPlanStep(task *Task, action Action) PlanStep {
+	return PlanStep{
+		TaskID:      task.ID,
+		Content:     task.Content,
+		Project:     task.Project,
+		Action:      action,
+		UpdatedDate: task.UpdatedDate,
+		Fingerprint: task.Fingerprint(),
+	}
+}
+
+// Fingerprint summarises the parts of a task an action may depend on.
+func (t *Task) Fingerprint() string {
+	tags := slices.Clone(t.Tags)
+	slices.Sort(tags)
+	due := ""
+	if t.DueDate != nil {
+		due = t.DueDate.UTC().Format(time.RFC3339)
+	}
+
+	hash := fnv.New64a()
+	for _, part := range []string{t.Content, t.Description, t.ProjectID, strings.Join(tags, ","), t.Status.String(), t.Priority.String(), due} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return fmt.Sprintf("%016x", hash.Sum64())
+}
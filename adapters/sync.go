@@ -0,0 +1,22 @@
+package adapters
+
+import "time"
+
+// SyncState pairs the tasks of two task managers kept in sync, with snapshots
+// of both sides as they were after the last sync to tell which side changed.
+type SyncState struct {
+	TaskManagers []string   `json:"taskmanagers"`
+	SyncedAt     time.Time  `json:"synced_at"`
+	Links        []SyncLink `json:"links"`
+}
+
+// SyncLink is one task as it exists in both task managers, keyed by the task
+// manager's name.
+type SyncLink struct {
+	Tasks map[string]Task `json:"tasks"`
+}
+
+// ID returns the task's ID in the given task manager.
+func (l *SyncLink) ID(taskManager string) string {
+	return l.Tasks[taskManager].ID
+}
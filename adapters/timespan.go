@@ -0,0 +1,225 @@
+package adapters
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeSpan is a calendar-aware span of time. Years and months are applied
+// before days, business days and finally the clock duration, so that
+// "1 month 2 days" behaves the way a person would count it.
+type TimeSpan struct {
+	Years        int
+	Months       int
+	Days         int
+	BusinessDays int
+	Duration     time.Duration
+}
+
+// maxSpanValue bounds each number in a span; anything larger is a typo
+// rather than a timespan, and would overflow durations.
+const maxSpanValue = 100000
+
+var (
+	isoDurationPattern = regexp.MustCompile(`^p(?:(\d+)y)?(?:(\d+)m)?(?:(\d+)w)?(?:(\d+)d)?(?:t(?:(\d+)h)?(?:(\d+)m)?(?:(\d+)s)?)?$`)
+	spanPartPattern    = regexp.MustCompile(`^\s*(\d+)\s*(business\s*days?|[a-z]+)`)
+	spanSeparators     = strings.NewReplacer(",", " ", " and ", " ")
+)
+
+var spanUnits = map[string]func(span *TimeSpan, value int){
+	"y":            func(s *TimeSpan, v int) { s.Years += v },
+	"yr":           func(s *TimeSpan, v int) { s.Years += v },
+	"yrs":          func(s *TimeSpan, v int) { s.Years += v },
+	"year":         func(s *TimeSpan, v int) { s.Years += v },
+	"years":        func(s *TimeSpan, v int) { s.Years += v },
+	"q":            func(s *TimeSpan, v int) { s.Months += 3 * v },
+	"quarter":      func(s *TimeSpan, v int) { s.Months += 3 * v },
+	"quarters":     func(s *TimeSpan, v int) { s.Months += 3 * v },
+	"mo":           func(s *TimeSpan, v int) { s.Months += v },
+	"month":        func(s *TimeSpan, v int) { s.Months += v },
+	"months":       func(s *TimeSpan, v int) { s.Months += v },
+	"w":            func(s *TimeSpan, v int) { s.Days += 7 * v },
+	"wk":           func(s *TimeSpan, v int) { s.Days += 7 * v },
+	"week":         func(s *TimeSpan, v int) { s.Days += 7 * v },
+	"weeks":        func(s *TimeSpan, v int) { s.Days += 7 * v },
+	"d":            func(s *TimeSpan, v int) { s.Days += v },
+	"day":          func(s *TimeSpan, v int) { s.Days += v },
+	"days":         func(s *TimeSpan, v int) { s.Days += v },
+	"bd":           func(s *TimeSpan, v int) { s.BusinessDays += v },
+	"businessday":  func(s *TimeSpan, v int) { s.BusinessDays += v },
+	"businessdays": func(s *TimeSpan, v int) { s.BusinessDays += v },
+	"h":            func(s *TimeSpan, v int) { s.Duration += time.Duration(v) * time.Hour },
+	"hr":           func(s *TimeSpan, v int) { s.Duration += time.Duration(v) * time.Hour },
+	"hour":         func(s *TimeSpan, v int) { s.Duration += time.Duration(v) * time.Hour },
+	"hours":        func(s *TimeSpan, v int) { s.Duration += time.Duration(v) * time.Hour },
+	"m":            func(s *TimeSpan, v int) { s.Duration += time.Duration(v) * time.Minute },
+	"min":          func(s *TimeSpan, v int) { s.Duration += time.Duration(v) * time.Minute },
+	"mins":         func(s *TimeSpan, v int) { s.Duration += time.Duration(v) * time.Minute },
+	"minute":       func(s *TimeSpan, v int) { s.Duration += time.Duration(v) * time.Minute },
+	"minutes":      func(s *TimeSpan, v int) { s.Duration += time.Duration(v) * time.Minute },
+	"s":            func(s *TimeSpan, v int) { s.Duration += time.Duration(v) * time.Second },
+	"sec":          func(s *TimeSpan, v int) { s.Duration += time.Duration(v) * time.Second },
+	"second":       func(s *TimeSpan, v int) { s.Duration += time.Duration(v) * time.Second },
+	"seconds":      func(s *TimeSpan, v int) { s.Duration += time.Duration(v) * time.Second },
+}
+
+// NewTimeSpan parses spans such as "1 month", "90 days ago", "1y", "2w3d",
+// "1 year, 2 months and 3 days", "1q", "5 business days" (or "5bd") and ISO
+// 8601 durations like "P6W" or "P1Y2M3DT4H". Note that in the short form "m"
+// means minutes and "mo" months.
+func NewTimeSpan(input string) (*TimeSpan, error) {
+	normalized := strings.ToLower(strings.TrimSpace(input))
+	normalized = strings.TrimSpace(strings.TrimSuffix(normalized, " ago"))
+	if normalized == "" {
+		return nil, fmt.Errorf("invalid duration string: %s", input)
+	}
+
+	if strings.HasPrefix(normalized, "p") {
+		span, ok := parseISODuration(normalized)
+		if !ok {
+			return nil, fmt.Errorf("invalid ISO 8601 duration: %s", input)
+		}
+		return span, nil
+	}
+
+	span := TimeSpan{}
+	remaining := spanSeparators.Replace(normalized)
+	for strings.TrimSpace(remaining) != "" {
+		matches := spanPartPattern.FindStringSubmatch(remaining)
+		if matches == nil {
+			return nil, fmt.Errorf("invalid duration string: %s", input)
+		}
+		unit := strings.Join(strings.Fields(matches[2]), "")
+		apply, ok := spanUnits[unit]
+		if !ok {
+			return nil, fmt.Errorf("unknown unit %q in duration string: %s", matches[2], input)
+		}
+		value, err := strconv.Atoi(matches[1])
+		if err != nil || value > maxSpanValue {
+			return nil, fmt.Errorf("invalid duration string: %s", input)
+		}
+		apply(&span, value)
+		remaining = remaining[len(matches[0]):]
+	}
+	return &span, nil
+}
+
+func parseISODuration(input string) (*TimeSpan, bool) {
+	matches := isoDurationPattern.FindStringSubmatch(input)
+	if matches == nil || input == "p" || strings.HasSuffix(input, "t") {
+		return nil, false
+	}
+
+	values := make([]int, len(matches))
+	for i, match := range matches[1:] {
+		if match == "" {
+			continue
+		}
+		value, err := strconv.Atoi(match)
+		if err != nil || value > maxSpanValue {
+			return nil, false
+		}
+		values[i+1] = value
+	}
+	return &TimeSpan{
+		Years:  values[1],
+		Months: values[2],
+		Days:   7*values[3] + values[4],
+		Duration: time.Duration(values[5])*time.Hour +
+			time.Duration(values[6])*time.Minute +
+			time.Duration(values[7])*time.Second,
+	}, true
+}
+
+func (t TimeSpan) ModifyDate(date time.Time, add bool) time.Time {
+	sign := 1
+	if !add {
+		sign = -1
+	}
+
+	date = addMonthsClamped(date, sign*(12*t.Years+t.Months))
+	date = date.AddDate(0, 0, sign*t.Days)
+	date = addBusinessDays(date, sign*t.BusinessDays)
+	return date.Add(time.Duration(sign) * t.Duration)
+}
+
+// addMonthsClamped moves date by the given number of months, clamping the day
+// to the end of the target month (Jan 31 + 1 month is Feb 28/29, not Mar 3
+// as time.AddDate would have it).
+func addMonthsClamped(date time.Time, months int) time.Time {
+	if months == 0 {
+		return date
+	}
+	year, month, day := date.Date()
+	hour, minute, second := date.Clock()
+	firstOfMonth := time.Date(year, month+time.Month(months), 1, hour, minute, second, date.Nanosecond(), date.Location())
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	return firstOfMonth.AddDate(0, 0, min(day, lastDay)-1)
+}
+
+// addBusinessDays steps over the given number of weekdays, skipping
+// Saturdays and Sundays. Once on a weekday, every five of them are a week.
+func addBusinessDays(date time.Time, days int) time.Time {
+	step := 1
+	if days < 0 {
+		step = -1
+		days = -days
+	}
+	for days > 0 {
+		date = date.AddDate(0, 0, step)
+		if date.Weekday() != time.Saturday && date.Weekday() != time.Sunday {
+			days--
+			break
+		}
+	}
+	date = date.AddDate(0, 0, step*7*(days/5))
+	for days %= 5; days > 0; {
+		date = date.AddDate(0, 0, step)
+		if date.Weekday() != time.Saturday && date.Weekday() != time.Sunday {
+			days--
+		}
+	}
+	return date
+}
+
+func (t TimeSpan) IsZero() bool {
+	return t == TimeSpan{}
+}
+
+func (t TimeSpan) String() string {
+	var parts []string
+	appendPart := func(value int, unit string) {
+		if value == 0 {
+			return
+		}
+		if value != 1 {
+			unit += "s"
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", value, unit))
+	}
+
+	appendPart(t.Years, "year")
+	appendPart(t.Months, "month")
+	if t.Days%7 == 0 {
+		appendPart(t.Days/7, "week")
+	} else {
+		appendPart(t.Days, "day")
+	}
+	appendPart(t.BusinessDays, "business day")
+	appendPart(int(t.Duration/time.Hour), "hour")
+	appendPart(int(t.Duration%time.Hour/time.Minute), "minute")
+	appendPart(int(t.Duration%time.Minute/time.Second), "second")
+
+	if len(parts) == 0 {
+		return "0 days"
+	}
+	return strings.Join(parts, " ")
+}
+
+func ValidateDurationString(input string) (bool, error) {
+	_, err := NewTimeSpan(input)
+	return err == nil, nil
+}
@@ -0,0 +1,126 @@
+package adapters
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewTimeSpan(t *testing.T) {
+	tests := []struct {
+		input string
+		want  TimeSpan
+	}{
+		{"1y", TimeSpan{Years: 1}},
+		{"1q", TimeSpan{Months: 3}},
+		{"2mo", TimeSpan{Months: 2}},
+		{"2w3d", TimeSpan{Days: 17}},
+		{"90 days ago", TimeSpan{Days: 90}},
+		{"30m", TimeSpan{Duration: 30 * time.Minute}},
+		{"1h30m", TimeSpan{Duration: 90 * time.Minute}},
+		{"1 month", TimeSpan{Months: 1}},
+		{"1 year, 2 months and 3 days", TimeSpan{Years: 1, Months: 2, Days: 3}},
+		{"2 Weeks 4 hours", TimeSpan{Days: 14, Duration: 4 * time.Hour}},
+		{"5 business days", TimeSpan{BusinessDays: 5}},
+		{"1 business day", TimeSpan{BusinessDays: 1}},
+		{"5bd", TimeSpan{BusinessDays: 5}},
+		{"1w 2bd", TimeSpan{Days: 7, BusinessDays: 2}},
+		{"P6W", TimeSpan{Days: 42}},
+		{"P1Y2M3DT4H", TimeSpan{Years: 1, Months: 2, Days: 3, Duration: 4 * time.Hour}},
+		{"PT1H30M", TimeSpan{Duration: 90 * time.Minute}},
+		{"p1m", TimeSpan{Months: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := NewTimeSpan(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != tt.want {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTimeSpanInvalid(t *testing.T) {
+	for _, input := range []string{"", "ago", "month", "3 fortnights", "1y two", "P", "PT", "P1H", "999999999bd", "P999999999D", "99999999999999999999d"} {
+		t.Run(input, func(t *testing.T) {
+			if span, err := NewTimeSpan(input); err == nil {
+				t.Errorf("got %+v, want an error", *span)
+			}
+		})
+	}
+}
+
+func TestModifyDate(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 9, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name string
+		span string
+		from time.Time
+		add  bool
+		want time.Time
+	}{
+		{"month end", "1 month", date(2026, time.January, 31), true, date(2026, time.February, 28)},
+		{"month end in leap year", "1 month", date(2028, time.January, 31), true, date(2028, time.February, 29)},
+		{"month end backwards", "1 month", date(2026, time.March, 31), false, date(2026, time.February, 28)},
+		{"quarter end", "1q", date(2026, time.May, 31), false, date(2026, time.February, 28)},
+		{"leap day next year", "1y", date(2028, time.February, 29), true, date(2029, time.February, 28)},
+		{"months before days", "1 month 1 day", date(2026, time.January, 31), true, date(2026, time.March, 1)},
+		{"business days over a weekend", "3bd", date(2026, time.October, 16), true, date(2026, time.October, 21)},
+		{"business days from a weekend", "5bd", date(2026, time.October, 17), true, date(2026, time.October, 23)},
+		{"business days backwards", "1bd", date(2026, time.October, 19), false, date(2026, time.October, 16)},
+		{"business weeks", "10bd", date(2026, time.October, 14), true, date(2026, time.October, 28)},
+		{"clock", "PT36H", date(2026, time.October, 19), true, date(2026, time.October, 20).Add(12 * time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			span, err := NewTimeSpan(tt.span)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := span.ModifyDate(tt.from, tt.add); !got.Equal(tt.want) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddBusinessDaysMatchesStepping(t *testing.T) {
+	stepped := func(date time.Time, days int) time.Time {
+		step := 1
+		if days < 0 {
+			step, days = -1, -days
+		}
+		for days > 0 {
+			date = date.AddDate(0, 0, step)
+			if date.Weekday() != time.Saturday && date.Weekday() != time.Sunday {
+				days--
+			}
+		}
+		return date
+	}
+	start := time.Date(2026, time.October, 12, 0, 0, 0, 0, time.UTC)
+	for offset := 0; offset < 7; offset++ {
+		from := start.AddDate(0, 0, offset)
+		for days := -23; days <= 23; days++ {
+			if got, want := addBusinessDays(from, days), stepped(from, days); !got.Equal(want) {
+				t.Errorf("%s %+dbd: got %s, want %s", from.Weekday(), days, got.Format(time.DateOnly), want.Format(time.DateOnly))
+			}
+		}
+	}
+}
+
+func TestAddBusinessDaysLargeSpan(t *testing.T) {
+	span, err := NewTimeSpan("100000bd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	want := from.AddDate(0, 0, 7*20000)
+	if got := span.ModifyDate(from, true); !got.Equal(want) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
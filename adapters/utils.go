@@ -2,63 +2,121 @@ package adapters
 
 import (
 	"fmt"
-	"regexp"
 	"strconv"
-	"time"
+	"strings"
 )
 
-type TimeSpan struct {
-	Value int
-	Unit  string
+var statusNames = map[Status]string{
+	StatusActive:    "active",
+	StatusCompleted: "completed",
+	StatusDeleted:   "deleted",
+	StatusNext:      "next",
+	StatusSomeday:   "someday",
+	StatusWaiting:   "waiting",
 }
 
-var durationPattern = regexp.MustCompile(`^(\d+) (minute|hour|day|week|month)s?$`)
+var priorityNames = map[Priority]string{
+	PriorityCritical: "critical",
+	PriorityHigh:     "high",
+	PriorityMedium:   "medium",
+	PriorityLow:      "low",
+}
 
-func NewTimeSpan(input string) (*TimeSpan, error) {
-	isValid, err := ValidateDurationString(input)
-	if err != nil {
-		return nil, err
+var energyNames = map[Energy]string{
+	EnergyLow:    "low",
+	EnergyMedium: "medium",
+	EnergyHigh:   "high",
+}
+
+var actionNames = map[Action]string{
+	ActionIgnore:     "ignore",
+	ActionComplete:   "complete",
+	ActionDelete:     "delete",
+	ActionRevalidate: "revalidate",
+	ActionDefer:      "defer",
+}
+
+func (s Status) String() string {
+	if name, ok := statusNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("status(%d)", s)
+}
+
+func ParseStatus(input string) (Status, error) {
+	for status, name := range statusNames {
+		if strings.EqualFold(input, name) {
+			return status, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown status: %s", input)
+}
+
+func (p Priority) String() string {
+	if name, ok := priorityNames[p]; ok {
+		return name
 	}
+	return fmt.Sprintf("priority(%d)", p)
+}
 
-	if !isValid {
-		return nil, fmt.Errorf("invalid duration string: %s", input)
+// ParsePriority accepts either a priority name (e.g. "high") or its numeric
+// value, where 1 is the most urgent.
+func ParsePriority(input string) (Priority, error) {
+	if value, err := strconv.Atoi(input); err == nil {
+		if _, ok := priorityNames[Priority(value)]; ok {
+			return Priority(value), nil
+		}
+		return 0, fmt.Errorf("priority out of range: %d", value)
+	}
+	for priority, name := range priorityNames {
+		if strings.EqualFold(input, name) {
+			return priority, nil
+		}
 	}
+	return 0, fmt.Errorf("unknown priority: %s", input)
+}
 
-	matches := durationPattern.FindStringSubmatch(input)
-	value, _ := strconv.Atoi(matches[1])
-	unit := matches[2]
+func (e Energy) String() string {
+	if name, ok := energyNames[e]; ok {
+		return name
+	}
+	return ""
+}
 
-	return &TimeSpan{
-		Value: value,
-		Unit:  unit,
-	}, nil
+func ParseEnergy(input string) (Energy, error) {
+	for energy, name := range energyNames {
+		if strings.EqualFold(input, name) {
+			return energy, nil
+		}
+	}
+	return EnergyUnknown, fmt.Errorf("unknown energy level: %s", input)
 }
 
-func (t TimeSpan) ModifyDate(date time.Time, add bool) time.Time {
-	multiplier := 1
-	if !add {
-		multiplier = -1
+func (a Action) String() string {
+	if name, ok := actionNames[a]; ok {
+		return name
 	}
-	switch t.Unit {
-	case "minute":
-		return date.Add(time.Minute * time.Duration(multiplier*t.Value))
-	case "hour":
-		return date.Add(time.Hour * time.Duration(multiplier*t.Value))
-	case "day":
-		return date.AddDate(0, 0, multiplier*t.Value)
-	case "week":
-		return date.AddDate(0, 0, multiplier*t.Value*7)
-	case "month":
-		return date.AddDate(0, multiplier*t.Value, 0)
-	default:
-		return date
+	return fmt.Sprintf("action(%d)", a)
+}
+
+func ParseAction(input string) (Action, error) {
+	for action, name := range actionNames {
+		if strings.EqualFold(input, name) {
+			return action, nil
+		}
 	}
+	return 0, fmt.Errorf("unknown action: %s", input)
 }
 
-func (t TimeSpan) String() string {
-	return fmt.Sprintf("%d %s", t.Value, t.Unit)
+func (a Action) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
 }
 
-func ValidateDurationString(input string) (bool, error) {
-	return durationPattern.MatchString(input), nil
+func (a *Action) UnmarshalText(text []byte) error {
+	action, err := ParseAction(string(text))
+	if err != nil {
+		return err
+	}
+	*a = action
+	return nil
 }
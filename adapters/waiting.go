@@ -0,0 +1,93 @@
+package adapters
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WaitingInfo describes a delegated task. Task managers without dedicated
+// fields keep it as "key: value" lines in the task description, see
+// ParseWaitingInfo and FormatWaitingInfo.
+type WaitingInfo struct {
+	DelegatedTo string     `json:"delegated_to,omitempty"`
+	Since       *time.Time `json:"since,omitempty"`
+	FollowedUp  *time.Time `json:"followed_up,omitempty"`
+}
+
+const (
+	waitingForKey = "Waiting for"
+	sinceKey      = "Since"
+	followedUpKey = "Followed up"
+)
+
+// LastContact is when the task was last handed over or followed up on.
+func (w *WaitingInfo) LastContact() *time.Time {
+	if w.FollowedUp != nil && (w.Since == nil || w.FollowedUp.After(*w.Since)) {
+		return w.FollowedUp
+	}
+	return w.Since
+}
+
+// ParseWaitingInfo extracts waiting-for details from a description,
+// returning nil when it holds none.
+func ParseWaitingInfo(description string) *WaitingInfo {
+	info := WaitingInfo{}
+	found := false
+	for _, line := range strings.Split(description, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case waitingForKey:
+			info.DelegatedTo, found = value, true
+		case sinceKey:
+			if date, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+				info.Since, found = &date, true
+			}
+		case followedUpKey:
+			if date, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+				info.FollowedUp, found = &date, true
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+	return &info
+}
+
+// FormatWaitingInfo writes the waiting-for details into description,
+// replacing any previous ones and keeping the rest of it intact.
+func FormatWaitingInfo(description string, info *WaitingInfo) string {
+	var kept []string
+	for _, line := range strings.Split(description, "\n") {
+		key, _, ok := strings.Cut(line, ":")
+		switch strings.TrimSpace(key) {
+		case waitingForKey, sinceKey, followedUpKey:
+			if ok {
+				continue
+			}
+		}
+		kept = append(kept, line)
+	}
+
+	var lines []string
+	if info.DelegatedTo != "" {
+		lines = append(lines, fmt.Sprintf("%s: %s", waitingForKey, info.DelegatedTo))
+	}
+	if info.Since != nil {
+		lines = append(lines, fmt.Sprintf("%s: %s", sinceKey, info.Since.Format("2006-01-02")))
+	}
+	if info.FollowedUp != nil {
+		lines = append(lines, fmt.Sprintf("%s: %s", followedUpKey, info.FollowedUp.Format("2006-01-02")))
+	}
+
+	rest := strings.TrimSpace(strings.Join(kept, "\n"))
+	if rest != "" {
+		lines = append(lines, "", rest)
+	}
+	return strings.Join(lines, "\n")
+}
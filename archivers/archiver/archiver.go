@@ -0,0 +1,31 @@
+package archiver
+
+import (
+	"github.com/dormunis/gitd/adapters"
+	"github.com/dormunis/gitd/archivers/markdown"
+)
+
+// Initialize creates the archiver configured under the given name.
+func Initialize(name string, settings adapters.Settings) (adapters.ArchiverAdapter, error) {
+	config, ok := settings.Archivers[name]
+	if !ok {
+		return nil, adapters.Errorf(adapters.ErrorConfig, "Unknown archiver: %s", name)
+	}
+
+	var adapter adapters.ArchiverAdapter
+	switch config.Type {
+	case adapters.ArchiverTypeMarkdown, adapters.ArchiverTypeObsidian:
+		var e error
+		adapter, e = markdown.NewMarkdownArchiver()
+
+		if e != nil {
+			return nil, e
+		}
+	default:
+		return nil, adapters.Errorf(adapters.ErrorConfig, "Unknown archiver type: %v", config.Type)
+	}
+	if err := adapter.Initialize(config); err != nil {
+		return nil, adapters.NewError(adapters.ErrorConfig, err)
+	}
+	return adapter, nil
+}
@@ -0,0 +1,248 @@
+package markdown
+
+import (
+	"errors"
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	defaultFilename   = "gitd-archive.md"
+	defaultDateFormat = "2006-01-02"
+	defaultDailyNote  = "{date}.md"
+
+	dailyNoteStart = "<!-- gitd:start -->"
+	dailyNoteEnd   = "<!-- gitd:end -->"
+)
+
+// MarkdownArchiver documents the outcome of reviews as Markdown notes. With
+// the obsidian type, projects are written as [[wikilinks]] and tags as #tags.
+type MarkdownArchiver struct {
+	config adapters.ArchiverConfig
+}
+
+func (m *MarkdownArchiver) Initialize(config adapters.ArchiverConfig) error {
+	if config.Path == "" {
+		return errors.New("archiver path is not configured")
+	}
+	path, err := expandHome(config.Path)
+	if err != nil {
+		return err
+	}
+	config.Path = path
+	if config.Filename == "" {
+		config.Filename = defaultFilename
+	}
+	if config.DateFormat == "" {
+		config.DateFormat = defaultDateFormat
+	}
+	if config.DailyNote == "" {
+		config.DailyNote = defaultDailyNote
+	}
+	if config.Mode == "" {
+		config.Mode = adapters.ArchiveModeAppend
+	}
+	m.config = config
+	return nil
+}
+
+func (m *MarkdownArchiver) Archive(title string, actions *[]adapters.TaskAction) error {
+	var lines []string
+	for _, action := range *actions {
+		if line, ok := m.formatAction(&action); ok {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	var note strings.Builder
+	fmt.Fprintf(&note, "## %s %s\n\n", now.Format("2006-01-02 15:04"), title)
+	for _, line := range lines {
+		note.WriteString(line + "\n")
+	}
+	note.WriteString("\n")
+
+	return m.write(now, note.String())
+}
+
+// ArchiveArticles documents read-later items as links, with their summary
+// when there is one.
+func (m *MarkdownArchiver) ArchiveArticles(title string, articles *[]adapters.Article) error {
+	if len(*articles) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	var note strings.Builder
+	fmt.Fprintf(&note, "## %s %s\n\n", now.Format("2006-01-02 15:04"), title)
+	for _, article := range *articles {
+		checkbox := "[ ]"
+		if article.Read {
+			checkbox = "[x]"
+		}
+		link := article.Title
+		if article.URL != "" {
+			link = fmt.Sprintf("[%s](%s)", article.Title, article.URL)
+		}
+		note.WriteString(m.taskLine(checkbox, link, "", article.Task) + "\n")
+		if article.Summary != "" {
+			note.WriteString("    - " + article.Summary + "\n")
+		}
+	}
+	note.WriteString("\n")
+
+	return m.write(now, note.String())
+}
+
+func (m *MarkdownArchiver) formatAction(action *adapters.TaskAction) (string, bool) {
+	var checkbox, content, outcome string
+	switch action.Action {
+	case adapters.ActionComplete:
+		checkbox, content, outcome = "[x]", action.Task.Content, "completed"
+	case adapters.ActionDelete:
+		checkbox, content, outcome = "[ ]", "~~"+action.Task.Content+"~~", "deleted"
+	case adapters.ActionDefer:
+		checkbox, content, outcome = "[ ]", action.Task.Content, "deferred to someday/maybe"
+	default:
+		return "", false
+	}
+
+	return m.taskLine(checkbox, content, " - "+outcome, action.Task), true
+}
+
+// taskLine formats a task as a Markdown list item with its project and tags.
+func (m *MarkdownArchiver) taskLine(checkbox string, content string, suffix string, task *adapters.Task) string {
+	project := task.Project
+	var tags []string
+	if m.config.Type == adapters.ArchiverTypeObsidian {
+		project = "[[" + project + "]]"
+		for _, tag := range task.Tags {
+			tags = append(tags, "#"+tag)
+		}
+	} else {
+		for _, tag := range task.Tags {
+			tags = append(tags, "`"+tag+"`")
+		}
+	}
+
+	line := fmt.Sprintf("- %s %s (%s)%s", checkbox, content, project, suffix)
+	if len(tags) > 0 {
+		line += " " + strings.Join(tags, " ")
+	}
+	return line
+}
+
+// WriteDailyNote writes the plan into the day's note. Running it again the
+// same day only replaces the generated part, keeping anything written around
+// it.
+func (m *MarkdownArchiver) WriteDailyNote(note *adapters.DailyNote) error {
+	var block strings.Builder
+	block.WriteString(dailyNoteStart + "\n")
+	m.writeDailySection(&block, "Plan", note.Planned, "[ ]", nil)
+	m.writeDailySection(&block, "Overdue", note.Overdue, "[ ]", func(task *adapters.Task) string {
+		return " - due " + task.DueDate.Format("2006-01-02")
+	})
+	m.writeDailySection(&block, "Due today", note.Due, "[ ]", nil)
+	m.writeDailySection(&block, "Next actions", note.Next, "[ ]", nil)
+	m.writeDailySection(&block, "Completed yesterday", note.Completed, "[x]", nil)
+	block.WriteString(dailyNoteEnd)
+
+	filename := strings.ReplaceAll(m.config.DailyNote, "{date}", note.Date.Format("2006-01-02"))
+	path := filepath.Join(m.config.Path, filename)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	existing, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		content := fmt.Sprintf("# %s\n\n%s\n\n## Journal\n\n", note.Date.Format("Monday, January 2, 2006"), block.String())
+		return os.WriteFile(path, []byte(content), 0o644)
+	}
+	if err != nil {
+		return err
+	}
+
+	content := string(existing)
+	start := strings.Index(content, dailyNoteStart)
+	end := strings.Index(content, dailyNoteEnd)
+	if start == -1 || end < start {
+		content = strings.TrimRight(content, "\n") + "\n\n" + block.String() + "\n"
+	} else {
+		content = content[:start] + block.String() + content[end+len(dailyNoteEnd):]
+	}
+	return os.WriteFile(path, []byte(content), 0o644)
+}
+
+func (m *MarkdownArchiver) writeDailySection(block *strings.Builder, title string, tasks []adapters.Task, checkbox string, suffix func(task *adapters.Task) string) {
+	if len(tasks) == 0 {
+		return
+	}
+	fmt.Fprintf(block, "## %s\n\n", title)
+	for i := range tasks {
+		extra := ""
+		if suffix != nil {
+			extra = suffix(&tasks[i])
+		}
+		block.WriteString(m.taskLine(checkbox, tasks[i].Content, extra, &tasks[i]) + "\n")
+	}
+	block.WriteString("\n")
+}
+
+func (m *MarkdownArchiver) write(now time.Time, content string) error {
+	if err := os.MkdirAll(m.config.Path, 0o755); err != nil {
+		return err
+	}
+
+	filename := strings.ReplaceAll(m.config.Filename, "{date}", now.Format(m.config.DateFormat))
+	path := filepath.Join(m.config.Path, filename)
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if m.config.Mode == adapters.ArchiveModeNew {
+		path = uniquePath(path)
+		flags = os.O_CREATE | os.O_WRONLY | os.O_EXCL
+	}
+
+	file, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.WriteString(content)
+	return err
+}
+
+// uniquePath appends a counter to the filename until it no longer exists,
+// e.g. "archive.md", "archive 2.md", "archive 3.md".
+func uniquePath(path string) string {
+	extension := filepath.Ext(path)
+	base := strings.TrimSuffix(path, extension)
+	candidate := path
+	for i := 2; ; i++ {
+		if _, err := os.Stat(candidate); errors.Is(err, os.ErrNotExist) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s %d%s", base, i, extension)
+	}
+}
+
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
+
+func NewMarkdownArchiver() (adapters.ArchiverAdapter, error) {
+	return &MarkdownArchiver{}, nil
+}
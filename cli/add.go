@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// Add captures tasks written in a compact syntax, see parseCapture, into the
+// task manager in a single batch.
+func Add(taskManager adapters.TaskManagerAdapter, lines []string) error {
+	var captures []capture
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parsed, err := parseCapture(line)
+		if err != nil {
+			if len(lines) > 1 {
+				return adapters.Errorf(adapters.ErrorValidation, "line %d: %w", i+1, err)
+			}
+			return adapters.NewError(adapters.ErrorValidation, err)
+		}
+		captures = append(captures, *parsed)
+	}
+	if len(captures) == 0 {
+		return adapters.Errorf(adapters.ErrorValidation, "nothing to add")
+	}
+
+	drafts, err := resolveCaptures(taskManager, captures)
+	if err != nil {
+		return adapters.NewError(adapters.ErrorValidation, err)
+	}
+	if err := taskManager.CreateTasks(&drafts); err != nil {
+		return err
+	}
+	for _, c := range captures {
+		destination := "inbox"
+		if c.Project != "" {
+			destination = c.Project
+		}
+		fmt.Printf("Added %q to %s\n", c.Draft.Content, destination)
+	}
+	return nil
+}
+
+func readCaptureLines(reader io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+type capture struct {
+	Draft   adapters.TaskDraft
+	Project string // project name, resolved into Draft.ProjectID
+}
+
+// parseCapture understands inline markers in a task line:
+//
+//	Call dentist @phone +Personal !2 due:friday
+//
+// @label adds a label (context), +Project picks the project (instead of the
+// inbox), !N sets the priority (1 is the most urgent) and due: sets the due
+// date in the task manager's natural language. Values containing spaces can be
+// quoted, e.g. +"Home Improvement" or due:"next monday".
+func parseCapture(line string) (*capture, error) {
+	tokens, err := splitCaptureTokens(line)
+	if err != nil {
+		return nil, err
+	}
+
+	c := capture{}
+	var words []string
+	for _, token := range tokens {
+		switch {
+		case len(token) > 1 && token[0] == '@':
+			c.Draft.Tags = appendUnique(c.Draft.Tags, token[1:])
+		case len(token) > 1 && token[0] == '+':
+			if c.Project != "" {
+				return nil, fmt.Errorf("more than one project in %q", line)
+			}
+			c.Project = token[1:]
+		case len(token) > 1 && token[0] == '!' && isPriority(token[1:]):
+			c.Draft.Priority, _ = adapters.ParsePriority(token[1:])
+		case strings.HasPrefix(token, "due:") && len(token) > len("due:"):
+			due := strings.TrimPrefix(token, "due:")
+			c.Draft.Due = &due
+		default:
+			words = append(words, token)
+		}
+	}
+
+	c.Draft.Content = strings.Join(words, " ")
+	if c.Draft.Content == "" {
+		return nil, fmt.Errorf("missing task content in %q", line)
+	}
+	return &c, nil
+}
+
+func isPriority(value string) bool {
+	_, err := adapters.ParsePriority(value)
+	return err == nil
+}
+
+// splitCaptureTokens splits on whitespace, keeping double-quoted sections
+// (which may appear mid-token, as in due:"next monday") together.
+func splitCaptureTokens(line string) ([]string, error) {
+	var tokens []string
+	var current strings.Builder
+	inQuotes := false
+	for _, r := range line {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case unicode.IsSpace(r) && !inQuotes:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote in %q", line)
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens, nil
+}
+
+func resolveCaptures(taskManager adapters.TaskManagerAdapter, captures []capture) ([]adapters.TaskDraft, error) {
+	var projects []adapters.Project
+	drafts := make([]adapters.TaskDraft, 0, len(captures))
+	for _, c := range captures {
+		if c.Project != "" {
+			if projects == nil {
+				var err error
+				if projects, err = taskManager.FetchProjects(); err != nil {
+					return nil, err
+				}
+			}
+			project, err := findProject(projects, c.Project)
+			if err != nil {
+				return nil, err
+			}
+			c.Draft.ProjectID = project.ID
+		}
+		drafts = append(drafts, c.Draft)
+	}
+	return drafts, nil
+}
@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+type launcherFormat string
+
+const (
+	launcherAlfred launcherFormat = "alfred"
+	launcherJSON   launcherFormat = "json"
+	launcherLines  launcherFormat = "lines"
+)
+
+// launcherRefreshInterval is how long a launcher search trusts the index
+// before refreshing it, as the launcher searches on every keystroke.
+const launcherRefreshInterval = time.Minute
+
+// launcherItem is an Alfred Script Filter item. Arg is what gitd alfred do
+// runs when the item is picked, e.g. "complete 7025591234".
+type launcherItem struct {
+	UID          string                 `json:"uid,omitempty"`
+	Title        string                 `json:"title"`
+	Subtitle     string                 `json:"subtitle,omitempty"`
+	Arg          string                 `json:"arg,omitempty"`
+	Autocomplete string                 `json:"autocomplete,omitempty"`
+	Valid        bool                   `json:"valid"`
+	Mods         map[string]launcherMod `json:"mods,omitempty"`
+}
+
+type launcherMod struct {
+	Arg      string `json:"arg"`
+	Subtitle string `json:"subtitle"`
+	Valid    bool   `json:"valid"`
+}
+
+// launcherMods are the actions picked by holding a modifier key in Alfred.
+// Picking an item without one completes the task. Deleting is left out, as a
+// launcher cannot ask for confirmation.
+var launcherMods = []struct {
+	Key      string
+	Action   adapters.Action
+	Subtitle string
+}{
+	{"cmd", adapters.ActionDefer, "Defer to someday/maybe"},
+	{"alt", adapters.ActionRevalidate, "Mark as reviewed"},
+}
+
+var launcherDone = map[adapters.Action]string{
+	adapters.ActionComplete:   "Completed",
+	adapters.ActionDefer:      "Deferred",
+	adapters.ActionDelete:     "Deleted",
+	adapters.ActionRevalidate: "Revalidated",
+}
+
+func launcherFormatFlag(cmd *cobra.Command) (launcherFormat, error) {
+	value, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return "", err
+	}
+	switch format := launcherFormat(value); format {
+	case launcherAlfred, launcherJSON, launcherLines:
+		return format, nil
+	default:
+		return "", adapters.Errorf(adapters.ErrorValidation, "invalid --format %s, expected alfred, json or lines", value)
+	}
+}
+
+// AlfredSearch lists the tasks best matching text, see Search. Completed
+// tasks are shown but cannot be picked.
+func AlfredSearch(taskManager adapters.TaskManagerAdapter, text string, format launcherFormat) error {
+	if strings.TrimSpace(text) == "" {
+		return writeLauncherItems([]launcherItem{{Title: "Search tasks", Subtitle: "Type to search current and completed tasks"}}, format)
+	}
+	matches, err := searchTasks(taskManager, text, 20, false, launcherRefreshInterval)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var items []launcherItem
+	for _, match := range matches {
+		subtitle := strings.Join([]string{match.Project, match.State, formatAge(now.Sub(match.Date))}, " · ")
+		if match.State == "completed" {
+			items = append(items, launcherItem{UID: match.ID, Title: match.Content, Subtitle: subtitle})
+			continue
+		}
+		items = append(items, taskItem(match.ID, match.Content, subtitle))
+	}
+	if len(items) == 0 {
+		items = append(items, launcherItem{Title: "No matches found", Subtitle: text})
+	}
+	return writeLauncherItems(items, format)
+}
+
+// AlfredNext lists next actions, see Next, keeping the ones containing text.
+func AlfredNext(taskManager adapters.TaskManagerAdapter, text string, format launcherFormat) error {
+	nextActions, err := fetchNextActions(taskManager, adapters.FilterRequest{})
+	if err != nil {
+		return err
+	}
+
+	text = strings.ToLower(strings.TrimSpace(text))
+	var items []launcherItem
+	for _, task := range nextActions {
+		if !strings.Contains(strings.ToLower(task.Content), text) {
+			continue
+		}
+		details := []string{task.Project, task.Priority.String()}
+		if task.DueDate != nil {
+			details = append(details, "due "+task.DueDate.Format("2006-01-02"))
+		}
+		items = append(items, taskItem(task.ID, task.Content, strings.Join(details, " · ")))
+	}
+	if len(items) == 0 {
+		items = append(items, launcherItem{Title: "No next actions found"})
+	}
+	return writeLauncherItems(items, format)
+}
+
+// AlfredAdd previews how text would be captured, see Add.
+func AlfredAdd(text string, format launcherFormat) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return writeLauncherItems([]launcherItem{{Title: "Capture a task", Subtitle: "e.g. Call dentist @phone +Personal !2 due:friday"}}, format)
+	}
+	parsed, err := parseCapture(text)
+	if err != nil {
+		return writeLauncherItems([]launcherItem{{Title: "Cannot capture " + text, Subtitle: err.Error()}}, format)
+	}
+
+	details := []string{"to inbox"}
+	if parsed.Project != "" {
+		details[0] = "to " + parsed.Project
+	}
+	for _, tag := range parsed.Draft.Tags {
+		details = append(details, "@"+tag)
+	}
+	if parsed.Draft.Priority != 0 {
+		details = append(details, parsed.Draft.Priority.String())
+	}
+	if parsed.Draft.Due != nil {
+		details = append(details, "due "+*parsed.Draft.Due)
+	}
+	return writeLauncherItems([]launcherItem{{
+		Title:    "Add " + parsed.Draft.Content,
+		Subtitle: strings.Join(details, " · "),
+		Arg:      "add " + text,
+		Valid:    true,
+	}}, format)
+}
+
+// AlfredDo runs the arg of a picked item: an action followed by task IDs, such
+// as "complete 7025591234", or "add" followed by a task to capture.
+func AlfredDo(taskManager adapters.TaskManagerAdapter, arg string) error {
+	arg = strings.TrimSpace(arg)
+	if text, ok := strings.CutPrefix(arg, "add "); ok {
+		return Add(taskManager, []string{text})
+	}
+
+	fields := strings.Fields(arg)
+	if len(fields) < 2 {
+		return adapters.Errorf(adapters.ErrorValidation, "expected an action followed by task IDs, got %q", arg)
+	}
+	action, err := adapters.ParseAction(fields[0])
+	if err != nil || action == adapters.ActionIgnore {
+		return adapters.Errorf(adapters.ErrorValidation, "unknown action %q, expected complete, defer, delete or revalidate", fields[0])
+	}
+
+	tasks, err := taskManager.FetchTasks()
+	if err != nil {
+		return err
+	}
+	var actions []adapters.TaskAction
+	for _, id := range fields[1:] {
+		index := slices.IndexFunc(tasks, func(task adapters.Task) bool { return task.ID == id })
+		if index == -1 {
+			return adapters.Errorf(adapters.ErrorValidation, "task not found: %s", id)
+		}
+		actions = append(actions, adapters.TaskAction{Task: &tasks[index], Action: action})
+	}
+	if err := applyJournaled(&actions, func() error { return taskManager.UpdateTasks(&actions) }); err != nil {
+		return err
+	}
+	for _, taskAction := range actions {
+		fmt.Printf("%s %s\n", launcherDone[action], taskAction.Task.Content)
+	}
+	return nil
+}
+
+func taskItem(id string, content string, subtitle string) launcherItem {
+	item := launcherItem{
+		UID:          id,
+		Title:        content,
+		Subtitle:     subtitle,
+		Arg:          adapters.ActionComplete.String() + " " + id,
+		Autocomplete: content,
+		Valid:        true,
+		Mods:         make(map[string]launcherMod),
+	}
+	for _, mod := range launcherMods {
+		item.Mods[mod.Key] = launcherMod{Arg: mod.Action.String() + " " + id, Subtitle: mod.Subtitle, Valid: true}
+	}
+	return item
+}
+
+// writeLauncherItems prints items as an Alfred Script Filter, as a plain JSON
+// array, or as tab separated title, subtitle and arg lines for rofi and dmenu.
+func writeLauncherItems(items []launcherItem, format launcherFormat) error {
+	switch format {
+	case launcherJSON:
+		return writeJSON(os.Stdout, items)
+	case launcherLines:
+		flatten := strings.NewReplacer("\t", " ", "\n", " ")
+		for _, item := range items {
+			if item.Valid {
+				fmt.Printf("%s\t%s\t%s\n", flatten.Replace(item.Title), flatten.Replace(item.Subtitle), item.Arg)
+			}
+		}
+		return nil
+	default:
+		return writeJSON(os.Stdout, map[string][]launcherItem{"items": items})
+	}
+}
@@ -0,0 +1,23 @@
+package cli
+
+import (
+	"github.com/dormunis/gitd/adapters"
+	"strings"
+	"testing"
+)
+
+func TestTaskItemActions(t *testing.T) {
+	item := taskItem("7025591234", "Renew passport", "Personal")
+	if item.Arg != "complete 7025591234" {
+		t.Errorf("got arg %q, want complete", item.Arg)
+	}
+	for key, mod := range item.Mods {
+		action, _, _ := strings.Cut(mod.Arg, " ")
+		if action == adapters.ActionDelete.String() {
+			t.Errorf("%s deletes the task without confirmation", key)
+		}
+	}
+	if len(item.Mods) != 2 {
+		t.Errorf("got mods %v, want defer and revalidate", item.Mods)
+	}
+}
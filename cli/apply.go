@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"os"
+	"slices"
+	"strings"
+)
+
+// planActions prints the commands the actions would send, grouped by action,
+// and saves them to out when it is set. Nothing is sent.
+func planActions(taskManager adapters.TaskManagerAdapter, actions *[]adapters.TaskAction, out string, format outputFormat) error {
+	planner, ok := taskManager.(adapters.Planner)
+	if !ok {
+		return adapters.Errorf(adapters.ErrorValidation, "this task manager does not support dry runs")
+	}
+	plan, err := planner.PlanTasks(actions)
+	if err != nil {
+		return err
+	}
+
+	if out == "" {
+		return writeOutput(format, plan, func() { printPlan(plan) })
+	}
+	printPlan(plan)
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(out, data, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("Plan saved to %s, run `gitd apply %s` to apply it\n", out, out)
+	return nil
+}
+
+func printPlan(plan *adapters.Plan) {
+	if len(plan.Steps) == 0 {
+		fmt.Println("No changes")
+		return
+	}
+
+	steps := slices.Clone(plan.Steps)
+	slices.SortStableFunc(steps, func(a, b adapters.PlanStep) int { return int(a.Action) - int(b.Action) })
+	for i, step := range steps {
+		if i == 0 || steps[i-1].Action != step.Action {
+			count := 0
+			for _, other := range steps {
+				if other.Action == step.Action {
+					count++
+				}
+			}
+			fmt.Printf("%s (%d):\n", step.Action, count)
+		}
+		fmt.Printf("  %s (%s) [%s]\n", step.Content, step.Project, step.TaskID)
+		for _, command := range step.Commands {
+			fmt.Printf("    %s\n", command)
+		}
+	}
+}
+
+// Apply sends a saved plan, refusing to when any of its tasks changed since
+// the plan was made.
+func Apply(taskManager adapters.TaskManagerAdapter, path string, assumeYes bool) error {
+	planner, ok := taskManager.(adapters.Planner)
+	if !ok {
+		return adapters.Errorf(adapters.ErrorValidation, "this task manager does not support plans")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return adapters.NewError(adapters.ErrorValidation, err)
+	}
+	var plan adapters.Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return adapters.Errorf(adapters.ErrorValidation, "invalid plan file %s: %w", path, err)
+	}
+
+	tasks, err := taskManager.FetchTasks()
+	if err != nil {
+		return err
+	}
+	if stale := staleSteps(&plan, &tasks); len(stale) > 0 {
+		return adapters.Errorf(adapters.ErrorValidation, "the plan made on %s is outdated:\n  %s\nMake a new plan and apply that instead",
+			plan.CreatedAt.Format("2006-01-02 15:04"), strings.Join(stale, "\n  "))
+	}
+
+	printPlan(&plan)
+	if len(plan.Steps) == 0 {
+		return nil
+	}
+	if !assumeYes {
+		fmt.Printf("Are you sure you want to apply this plan? (y/n): ")
+		var response string
+		fmt.Scanln(&response)
+		if strings.ToLower(response) != "y" {
+			return adapters.ErrAborted
+		}
+	}
+	actions := planActionsOf(&plan, &tasks)
+	if err := applyJournaled(&actions, func() error { return planner.ApplyPlan(&plan) }); err != nil {
+		return err
+	}
+	fmt.Printf("Applied %d changes\n", len(plan.Steps))
+	return nil
+}
+
+// planActionsOf returns the actions of a plan, on the tasks as they are now.
+func planActionsOf(plan *adapters.Plan, tasks *[]adapters.Task) []adapters.TaskAction {
+	var actions []adapters.TaskAction
+	for _, step := range plan.Steps {
+		index := slices.IndexFunc(*tasks, func(task adapters.Task) bool { return task.ID == step.TaskID })
+		if index != -1 {
+			actions = append(actions, adapters.TaskAction{Task: &(*tasks)[index], Action: step.Action})
+		}
+	}
+	return actions
+}
+
+// staleSteps describes the tasks of the plan that are gone or were changed
+// since it was made.
+func staleSteps(plan *adapters.Plan, tasks *[]adapters.Task) []string {
+	byID := make(map[string]*adapters.Task)
+	for i := range *tasks {
+		byID[(*tasks)[i].ID] = &(*tasks)[i]
+	}
+
+	var stale []string
+	for _, step := range plan.Steps {
+		task, ok := byID[step.TaskID]
+		switch {
+		case !ok:
+			stale = append(stale, fmt.Sprintf("%s (%s) no longer exists", step.Content, step.TaskID))
+		case !task.UpdatedDate.Equal(step.UpdatedDate) || task.Fingerprint() != step.Fingerprint:
+			stale = append(stale, fmt.Sprintf("%s (%s) was changed", step.Content, step.TaskID))
+		}
+	}
+	return stale
+}
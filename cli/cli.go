@@ -1,10 +1,13 @@
 package cli
 
 import (
-	"fmt"
 	"github.com/dormunis/gitd/adapters"
+	"github.com/dormunis/gitd/formats"
 	"github.com/dormunis/gitd/taskmanagers/taskmanager"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	// TODO: use viper as well
@@ -18,6 +21,19 @@ var rootCmd = &cobra.Command{
 	Long: `github.com/dormunis/gitd is a CLI for managing tasks.
     It is designed to work with task managers like Todoist, etc.
     It is also designed to work with archive managers like Obsidian, Notion, etc.`,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := outputFlag(cmd); err != nil {
+			return err
+		}
+		if cmd.Name() == "help" || cmd.Name() == "completion" {
+			return nil
+		}
+		var err error
+		settings, err = adapters.GetSettings()
+		return err
+	},
 }
 
 // TODO: create registration process for adapters
@@ -32,39 +48,639 @@ var purgeCmd = &cobra.Command{
 	Use:   "purge",
 	Short: "Purge tasks",
 	Long:  `Purge old and irrelevant tasks from task manager`,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		taskManager, err := taskmanager.Initialize(taskmanager.Todoist, settings)
+		if err != nil {
+			return err
+		}
+
+		filterRequest, err := buildFilterRequest(cmd, taskManager, "timespan")
+		if err != nil {
+			return err
+		}
+
+		view, err := viewFlag(cmd)
+		if err != nil {
+			return err
+		}
+
+		options, err := purgeOptions(cmd)
+		if err != nil {
+			return err
+		}
+
+		format, err := outputFlag(cmd)
+		if err != nil {
+			return err
+		}
+		return Purge(taskManager, *filterRequest, *view, *options, format)
+	},
+}
+
+var applyCmd = &cobra.Command{
+	Use:   "apply <plan-file>",
+	Short: "Apply a saved plan",
+	Long: `Send the commands of a plan saved with --out, after checking that none of
+its tasks changed since the plan was made`,
+	Args: validArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		taskManager, err := taskmanager.Initialize(taskmanager.Todoist, settings)
+		if err != nil {
+			return err
+		}
+
+		assumeYes, _ := cmd.Flags().GetBool("yes")
+		return Apply(taskManager, args[0], assumeYes)
+	},
+}
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Undo the last applied actions",
+	Long: `Reverse the last purge or applied plan: uncomplete completed tasks,
+recreate deleted ones, restore labels and remove revalidation notes`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		taskManager, err := taskmanager.Initialize(taskmanager.Todoist, settings)
+		if err != nil {
+			return err
+		}
+
+		assumeYes, _ := cmd.Flags().GetBool("yes")
+		return Undo(taskManager, assumeYes)
+	},
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List tasks",
+	Long:  `List tasks from the task manager, optionally through a named view`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		taskManager, err := taskmanager.Initialize(taskmanager.Todoist, settings)
+		if err != nil {
+			return err
+		}
+
+		filterRequest, err := buildFilterRequest(cmd, taskManager, "")
+		if err != nil {
+			return err
+		}
+
+		view, err := viewFlag(cmd)
+		if err != nil {
+			return err
+		}
+
+		format, err := outputFlag(cmd)
+		if err != nil {
+			return err
+		}
+		return List(taskManager, *filterRequest, *view, format)
+	},
+}
+
+var weeklyCmd = &cobra.Command{
+	Use:   "weekly",
+	Short: "Weekly review",
+	Long: `Guided weekly review: empty the inbox, review next actions, waiting-for and
+someday/maybe items, find projects without a next action and purge stale items.
+An interrupted review resumes from the step it stopped at.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		taskManager, err := taskmanager.Initialize(taskmanager.Todoist, settings)
+		if err != nil {
+			return err
+		}
+
+		restart, _ := cmd.Flags().GetBool("restart")
+		return WeeklyReview(taskManager, restart)
+	},
+}
+
+var monthlyCmd = &cobra.Command{
+	Use:   "monthly",
+	Short: "Monthly review",
+	Long: `Show the month's completion statistics and review someday/maybe items,
+grouped by project: promote them to active, delete them or leave them for next month`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		taskManager, err := taskmanager.Initialize(taskmanager.Todoist, settings)
 		if err != nil {
-			// TODO: handle errors more gracefully
-			fmt.Println(err)
-			os.Exit(1)
+			return err
 		}
 
-		timespanString, err := cmd.Flags().GetString("timespan")
+		month := time.Now()
+		monthString, _ := cmd.Flags().GetString("month")
+		if monthString != "" {
+			month, err = time.ParseInLocation("2006-01", monthString, time.Local)
+			if err != nil {
+				return adapters.Errorf(adapters.ErrorValidation, "invalid month %q, expected YYYY-MM", monthString)
+			}
+		}
+		return MonthlyReview(taskManager, month)
+	},
+}
+
+var projectsCmd = &cobra.Command{
+	Use:   "projects",
+	Short: "Audit projects",
+	Long: `List active projects without a next action, or untouched for a while,
+and add a next action to them or archive them`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		taskManager, err := taskmanager.Initialize(taskmanager.Todoist, settings)
+		if err != nil {
+			return err
+		}
+
+		untouchedFor, err := timeSpanFlag(cmd, "untouched-for")
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			return err
 		}
-		timespan, err := adapters.NewTimeSpan(timespanString)
+		if untouchedFor == nil {
+			untouchedFor = &adapters.TimeSpan{}
+		}
+
+		format, err := outputFlag(cmd)
+		if err != nil {
+			return err
+		}
+		return ReviewProjects(taskManager, *untouchedFor, format)
+	},
+}
+
+var runReviewCmd = &cobra.Command{
+	Use:   "run <name>",
+	Short: "Run a configured review",
+	Long:  `Run a review process defined under reviews in the config file`,
+	Args:  validArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		taskManager, err := taskmanager.Initialize(taskmanager.Todoist, settings)
+		if err != nil {
+			return err
+		}
+
+		restart, _ := cmd.Flags().GetBool("restart")
+		return ReviewPipeline(taskManager, args[0], restart)
+	},
+}
+
+var inboxCmd = &cobra.Command{
+	Use:   "inbox",
+	Short: "Process the inbox",
+	Long: `Clarify inbox items one at a time: move them to a project, add labels,
+mark them as next or someday, set a due date, split them into subtasks or
+trash them. All changes are sent as one batch at the end.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		taskManager, err := taskmanager.Initialize(taskmanager.Todoist, settings)
+		if err != nil {
+			return err
+		}
+
+		return Inbox(taskManager)
+	},
+}
+
+var addCmd = &cobra.Command{
+	Use:   "add [task]",
+	Short: "Capture a task",
+	Long: `Capture a task into the inbox, e.g.:
+
+    gitd add "Call dentist @phone +Personal !2 due:friday"
+
+@label adds a label, +Project files it into a project instead of the inbox,
+!N sets the priority (1 is the most urgent) and due: sets the due date.
+With --stdin every line read is captured as a separate task.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fromStdin, _ := cmd.Flags().GetBool("stdin")
+		var lines []string
+		switch {
+		case fromStdin && len(args) > 0:
+			return adapters.Errorf(adapters.ErrorValidation, "cannot combine --stdin with a task argument")
+		case fromStdin:
+			var err error
+			lines, err = readCaptureLines(os.Stdin)
+			if err != nil {
+				return err
+			}
+		case len(args) > 0:
+			lines = []string{strings.Join(args, " ")}
+		default:
+			return adapters.Errorf(adapters.ErrorValidation, "nothing to add, pass a task or --stdin")
+		}
+
+		taskManager, err := taskmanager.Initialize(taskmanager.Todoist, settings)
+		if err != nil {
+			return err
+		}
+
+		return Add(taskManager, lines)
+	},
+}
+
+var waitingCmd = &cobra.Command{
+	Use:   "waiting",
+	Short: "Track waiting-for items",
+	Long:  `List waiting-for items by how long they have waited and follow up on them`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		taskManager, err := taskmanager.Initialize(taskmanager.Todoist, settings)
+		if err != nil {
+			return err
+		}
+
+		format, err := outputFlag(cmd)
+		if err != nil {
+			return err
+		}
+		return Waiting(taskManager, format)
+	},
+}
+
+var delegateCmd = &cobra.Command{
+	Use:   "delegate <task-id> <person>",
+	Short: "Mark a task as waiting for someone",
+	Args:  validArgs(cobra.ExactArgs(2)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		taskManager, err := taskmanager.Initialize(taskmanager.Todoist, settings)
+		if err != nil {
+			return err
+		}
+
+		return Delegate(taskManager, args[0], args[1])
+	},
+}
+
+var todayCmd = &cobra.Command{
+	Use:   "today",
+	Short: "Write today's daily note",
+	Long: `Write a daily note with today's due and overdue tasks, next actions and
+yesterday's completions to an archiver. With --plan, pick the tasks you commit
+to for the day first.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		taskManager, err := taskmanager.Initialize(taskmanager.Todoist, settings)
 		if err != nil {
-			fmt.Printf("Invalid timespan %s, error: %s", timespanString, err)
-			os.Exit(1)
+			return err
 		}
 
-		Purge(taskManager, *timespan)
+		archiverName, _ := cmd.Flags().GetString("archiver")
+		plan, _ := cmd.Flags().GetBool("plan")
+		return Today(taskManager, archiverName, plan)
 	},
 }
 
+var readCmd = &cobra.Command{
+	Use:   "read",
+	Short: "Review read-later items",
+	Long: `List tasks that are links or carry the read-later label. Mark them read,
+optionally with a summary, or move them to the reading list archive note.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		taskManager, err := taskmanager.Initialize(taskmanager.Todoist, settings)
+		if err != nil {
+			return err
+		}
+
+		format, err := outputFlag(cmd)
+		if err != nil {
+			return err
+		}
+		return Read(taskManager, format)
+	},
+}
+
+var searchCmd = &cobra.Command{
+	Use:   "search <text>",
+	Short: "Search current and completed tasks",
+	Long: `Full-text search over tasks, their descriptions and notes, and completed
+tasks. The local index is brought up to date before every search.`,
+	Args: validArgs(cobra.MinimumNArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		taskManager, err := taskmanager.Initialize(taskmanager.Todoist, settings)
+		if err != nil {
+			return err
+		}
+
+		limit, _ := cmd.Flags().GetInt("limit")
+		rebuild, _ := cmd.Flags().GetBool("rebuild")
+		format, err := outputFlag(cmd)
+		if err != nil {
+			return err
+		}
+		return Search(taskManager, strings.Join(args, " "), limit, rebuild, format)
+	},
+}
+
+var nextCmd = &cobra.Command{
+	Use:   "next",
+	Short: "Pick next actions",
+	Long:  `List next actions sorted by priority, due date and staleness`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		taskManager, err := taskmanager.Initialize(taskmanager.Todoist, settings)
+		if err != nil {
+			return err
+		}
+
+		filterRequest, err := buildFilterRequest(cmd, taskManager, "")
+		if err != nil {
+			return err
+		}
+
+		availableTime, err := cmd.Flags().GetString("time")
+		if err != nil {
+			return err
+		}
+		if availableTime != "" {
+			duration, err := time.ParseDuration(availableTime)
+			if err != nil {
+				return adapters.Errorf(adapters.ErrorValidation, "invalid --time %s: %w", availableTime, err)
+			}
+			filterRequest.MaxDuration = &duration
+		}
+
+		energyString, err := cmd.Flags().GetString("energy")
+		if err != nil {
+			return err
+		}
+		if energyString != "" {
+			energy, err := adapters.ParseEnergy(energyString)
+			if err != nil {
+				return adapters.NewError(adapters.ErrorValidation, err)
+			}
+			filterRequest.MaxEnergy = &energy
+		}
+
+		format, err := outputFlag(cmd)
+		if err != nil {
+			return err
+		}
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			format = outputJSON
+		}
+		one, _ := cmd.Flags().GetBool("one")
+		return Next(taskManager, *filterRequest, one, format)
+	},
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export tasks",
+	Long: `Export tasks as CSV, JSON, iCalendar VTODOs or todo.txt, e.g. to back up an
+account or feed tasks to other tools`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		formatName, _ := cmd.Flags().GetString("format")
+		format, err := formats.ParseFormat(formatName)
+		if err != nil {
+			return adapters.NewError(adapters.ErrorValidation, err)
+		}
+		completedSince, err := timeSpanFlag(cmd, "completed-since")
+		if err != nil {
+			return err
+		}
+
+		taskManager, err := taskmanager.Initialize(taskmanager.Todoist, settings)
+		if err != nil {
+			return err
+		}
+
+		filterRequest, err := buildFilterRequest(cmd, taskManager, "")
+		if err != nil {
+			return err
+		}
+
+		out, _ := cmd.Flags().GetString("out")
+		return Export(taskManager, *filterRequest, format, completedSince, out)
+	},
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import tasks",
+	Long: `Import tasks from CSV, JSON or todo.txt, creating the projects and labels they
+refer to. Completed tasks and tasks that already exist are skipped.
+
+Columns named after a field (content, description, project, tags, priority,
+due, notes, status, completed) or a common alias (title, list, labels, ...) are
+picked up, others can be mapped with --map, e.g. --map content=Title`,
+	Args: validArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		formatName, _ := cmd.Flags().GetString("format")
+		if formatName == "" {
+			switch strings.ToLower(filepath.Ext(args[0])) {
+			case ".json":
+				formatName = string(formats.FormatJSON)
+			case ".txt":
+				formatName = string(formats.FormatTodoTxt)
+			default:
+				formatName = string(formats.FormatCSV)
+			}
+		}
+		format, err := formats.ParseFormat(formatName)
+		if err != nil {
+			return adapters.NewError(adapters.ErrorValidation, err)
+		}
+		mapping, _ := cmd.Flags().GetStringToString("map")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		yes, _ := cmd.Flags().GetBool("yes")
+		allowDuplicates, _ := cmd.Flags().GetBool("allow-duplicates")
+
+		taskManager, err := taskmanager.Initialize(taskmanager.Todoist, settings)
+		if err != nil {
+			return err
+		}
+		return Import(taskManager, args[0], ImportOptions{
+			Format:          format,
+			Mapping:         mapping,
+			DryRun:          dryRun,
+			Yes:             yes,
+			AllowDuplicates: allowDuplicates,
+		})
+	},
+}
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Copy tasks between task managers",
+	Long: `Copy tasks from one task manager to another, and with --two-way back, keeping
+track of which tasks are the same so that later runs copy only what changed.
+When a task changed on both sides, the more recently updated version wins and
+the conflict is reported`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fromName, _ := cmd.Flags().GetString("from")
+		toName, _ := cmd.Flags().GetString("to")
+		if fromName == "" || toName == "" {
+			return adapters.Errorf(adapters.ErrorValidation, "both --from and --to are required")
+		}
+		from, err := taskmanager.ParseTaskManagerType(fromName)
+		if err != nil {
+			return err
+		}
+		to, err := taskmanager.ParseTaskManagerType(toName)
+		if err != nil {
+			return err
+		}
+		if from == to {
+			return adapters.Errorf(adapters.ErrorValidation, "cannot sync %s with itself", from)
+		}
+		twoWay, _ := cmd.Flags().GetBool("two-way")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		format, err := outputFlag(cmd)
+		if err != nil {
+			return err
+		}
+
+		fromManager, err := taskmanager.Initialize(from, settings)
+		if err != nil {
+			return err
+		}
+		toManager, err := taskmanager.Initialize(to, settings)
+		if err != nil {
+			return err
+		}
+		options := SyncOptions{From: string(from), To: string(to), TwoWay: twoWay, DryRun: dryRun}
+		return Sync(fromManager, toManager, options, format)
+	},
+}
+
+var alfredCmd = &cobra.Command{
+	Use:   "alfred",
+	Short: "Launcher integration",
+	Long: `Items for Alfred Script Filters, or for rofi, dmenu and Raycast with --format.
+Picking an item runs its arg through gitd alfred do.`,
+}
+
+var alfredSearchCmd = &cobra.Command{
+	Use:   "search [text]",
+	Short: "Search tasks",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := launcherFormatFlag(cmd)
+		if err != nil {
+			return err
+		}
+		taskManager, err := taskmanager.Initialize(taskmanager.Todoist, settings)
+		if err != nil {
+			return err
+		}
+		return AlfredSearch(taskManager, strings.Join(args, " "), format)
+	},
+}
+
+var alfredNextCmd = &cobra.Command{
+	Use:   "next [text]",
+	Short: "List next actions",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := launcherFormatFlag(cmd)
+		if err != nil {
+			return err
+		}
+		taskManager, err := taskmanager.Initialize(taskmanager.Todoist, settings)
+		if err != nil {
+			return err
+		}
+		return AlfredNext(taskManager, strings.Join(args, " "), format)
+	},
+}
+
+var alfredAddCmd = &cobra.Command{
+	Use:   "add [task]",
+	Short: "Preview a quick capture",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := launcherFormatFlag(cmd)
+		if err != nil {
+			return err
+		}
+		return AlfredAdd(strings.Join(args, " "), format)
+	},
+}
+
+var alfredDoCmd = &cobra.Command{
+	Use:   "do <action> <task-id>...",
+	Short: "Run the arg of a picked item",
+	Long: `Run the arg of a picked item: complete, defer, delete or revalidate followed
+by task IDs, or add followed by a task to capture`,
+	Args: validArgs(cobra.MinimumNArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		taskManager, err := taskmanager.Initialize(taskmanager.Todoist, settings)
+		if err != nil {
+			return err
+		}
+		return AlfredDo(taskManager, strings.Join(args, " "))
+	},
+}
+
+// validArgs reports wrong arguments as validation errors.
+func validArgs(args cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, values []string) error {
+		return adapters.NewError(adapters.ErrorValidation, args(cmd, values))
+	}
+}
+
 func init() {
-	settings = adapters.GetSettings()
+	rootCmd.PersistentFlags().StringP("output", "o", "table", "output format of listing commands: table, json or yaml")
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return adapters.NewError(adapters.ErrorValidation, err)
+	})
+	rootCmd.AddCommand(listCmd)
+	addFilterFlags(listCmd)
+	rootCmd.AddCommand(addCmd)
+	addCmd.Flags().Bool("stdin", false, "read tasks from stdin, one per line")
+	rootCmd.AddCommand(inboxCmd)
+	rootCmd.AddCommand(waitingCmd)
+	waitingCmd.AddCommand(delegateCmd)
+	rootCmd.AddCommand(todayCmd)
+	todayCmd.Flags().String("archiver", "", "archiver to write the note to (defaults to the only configured one)")
+	todayCmd.Flags().Bool("plan", false, "pick today's tasks before writing the note")
+	rootCmd.AddCommand(readCmd)
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().Int("limit", 10, "maximum number of matches to show")
+	searchCmd.Flags().Bool("rebuild", false, "rebuild the search index from scratch")
+	rootCmd.AddCommand(nextCmd)
+	addFilterFlags(nextCmd)
+	nextCmd.Flags().String("time", "", "available time, e.g. 30m; skips tasks estimated to take longer")
+	nextCmd.Flags().String("energy", "", "available energy (low, medium, high); skips tasks requiring more")
+	nextCmd.Flags().Bool("one", false, "print a single recommended next action")
+	nextCmd.Flags().Bool("json", false, "print next actions as JSON")
+	nextCmd.Flags().MarkDeprecated("json", "use --output json instead")
 	rootCmd.AddCommand(reviewCmd)
 	reviewCmd.AddCommand(purgeCmd)
-	purgeCmd.PersistentFlags().String("timespan", "1 month", "timespan to review")
+	reviewCmd.AddCommand(weeklyCmd)
+	weeklyCmd.Flags().Bool("restart", false, "discard saved progress and start the review over")
+	reviewCmd.AddCommand(monthlyCmd)
+	monthlyCmd.Flags().String("month", "", "month to report completions for, as YYYY-MM (defaults to the current month)")
+	reviewCmd.AddCommand(projectsCmd)
+	projectsCmd.Flags().String("untouched-for", "30 days", "also list projects without activity for this timespan")
+	reviewCmd.AddCommand(runReviewCmd)
+	runReviewCmd.Flags().Bool("restart", false, "discard saved progress and start the review over")
+	addPurgeFlags(purgeCmd)
+	rootCmd.AddCommand(exportCmd)
+	addFilterFlags(exportCmd)
+	exportCmd.Flags().String("format", "json", "export format: csv, json, ics or todotxt")
+	exportCmd.Flags().String("completed-since", "", "also export tasks completed within this timespan (e.g. \"1 year\")")
+	exportCmd.Flags().String("out", "", "file to write to instead of stdout")
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().String("format", "", "file format: csv, json or todotxt (default from the file extension)")
+	importCmd.Flags().StringToString("map", nil, "field=column pairs naming the column of a field")
+	importCmd.Flags().Bool("dry-run", false, "preview the import without creating anything")
+	importCmd.Flags().BoolP("yes", "y", false, "import without asking for confirmation")
+	importCmd.Flags().Bool("allow-duplicates", false, "import tasks that already exist")
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().String("from", "", "task manager to copy tasks from")
+	syncCmd.Flags().String("to", "", "task manager to copy tasks to")
+	syncCmd.Flags().Bool("two-way", false, "also copy new tasks and changes back")
+	syncCmd.Flags().Bool("dry-run", false, "report the changes without making them")
+	rootCmd.AddCommand(alfredCmd)
+	alfredCmd.PersistentFlags().String("format", "alfred", "item format: alfred (Script Filter JSON), json (plain array) or lines (title, subtitle and arg separated by tabs)")
+	alfredCmd.AddCommand(alfredSearchCmd)
+	alfredCmd.AddCommand(alfredNextCmd)
+	alfredCmd.AddCommand(alfredAddCmd)
+	alfredCmd.AddCommand(alfredDoCmd)
+	rootCmd.AddCommand(applyCmd)
+	rootCmd.AddCommand(undoCmd)
+	undoCmd.Flags().BoolP("yes", "y", false, "undo without asking for confirmation")
+	applyCmd.Flags().BoolP("yes", "y", false, "apply without asking for confirmation")
 }
 
+// Execute runs the command line and exits with the code documented for the
+// kind of error, if any.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	cmd, err := rootCmd.ExecuteC()
+	if err != nil {
+		format, _ := outputFlag(cmd)
+		reportError(err, format)
+		os.Exit(adapters.ExitCode(err))
 	}
 }
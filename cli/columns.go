@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"strings"
+	"time"
+)
+
+type taskColumn struct {
+	Title string
+	Width int
+	Value func(task *adapters.Task) string
+}
+
+var taskColumns = map[string]taskColumn{
+	"id":       {Title: "ID", Width: 12, Value: func(t *adapters.Task) string { return t.ID }},
+	"task":     {Title: "Task", Width: 65, Value: func(t *adapters.Task) string { return t.Content }},
+	"project":  {Title: "Project", Width: 30, Value: func(t *adapters.Task) string { return t.Project }},
+	"status":   {Title: "Status", Width: 10, Value: func(t *adapters.Task) string { return t.Status.String() }},
+	"priority": {Title: "Priority", Width: 10, Value: func(t *adapters.Task) string { return t.Priority.String() }},
+	"tags":     {Title: "Tags", Width: 25, Value: func(t *adapters.Task) string { return strings.Join(t.Tags, ",") }},
+	"created":  {Title: "Creation Date", Width: 18, Value: func(t *adapters.Task) string { return t.CreatedDate.Format("2006-01-02") }},
+	"updated":  {Title: "Last Modified Date", Width: 18, Value: func(t *adapters.Task) string { return t.UpdatedDate.Format("2006-01-02") }},
+	"due":      {Title: "Due Date", Width: 12, Value: func(t *adapters.Task) string { return formatOptionalDate(t.DueDate) }},
+	"duration": {Title: "Duration", Width: 10, Value: func(t *adapters.Task) string { return formatOptionalDuration(t.Duration) }},
+	"energy":   {Title: "Energy", Width: 8, Value: func(t *adapters.Task) string { return t.Energy.String() }},
+}
+
+var defaultColumns = []string{"task", "project", "created", "updated"}
+
+func resolveColumns(names []string) ([]taskColumn, error) {
+	if len(names) == 0 {
+		names = defaultColumns
+	}
+	columns := make([]taskColumn, 0, len(names))
+	for _, name := range names {
+		column, ok := taskColumns[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown column: %s", name)
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+func formatOptionalDate(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+	return date.Format("2006-01-02")
+}
+
+func formatOptionalDuration(duration *time.Duration) string {
+	if duration == nil {
+		return ""
+	}
+	return duration.String()
+}
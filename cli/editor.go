@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const editorErrorPrefix = "# error: "
+
+// editorActions maps the first column of the edited file to an action. The
+// one-letter forms match the keys of the purge TUI.
+var editorActions = map[string]adapters.Action{
+	"revalidate": adapters.ActionRevalidate,
+	"r":          adapters.ActionRevalidate,
+	"complete":   adapters.ActionComplete,
+	"v":          adapters.ActionComplete,
+	"defer":      adapters.ActionDefer,
+	"d":          adapters.ActionDefer,
+	"delete":     adapters.ActionDelete,
+	"x":          adapters.ActionDelete,
+	"ignore":     adapters.ActionIgnore,
+	"i":          adapters.ActionIgnore,
+}
+
+var errEditCancelled = &adapters.Error{Kind: adapters.ErrorAborted, Err: errors.New("edit cancelled, no changes made")}
+
+// editTasks lets the user pick the actions in $EDITOR, one line per task,
+// like kubectl edit: mistakes are marked with comments and the file is
+// opened again until it parses, or until it is saved without any change.
+func editTasks(tasks *[]adapters.Task, title string) ([]adapters.TaskAction, error) {
+	file, err := os.CreateTemp("", "gitd-purge-*.txt")
+	if err != nil {
+		return nil, err
+	}
+	path := file.Name()
+	defer os.Remove(path)
+	// Editors may save by renaming a new file over path, so every pass writes
+	// by path rather than through this handle.
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+
+	content := formatEditorFile(tasks, title)
+	for {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			return nil, err
+		}
+		if err := runEditor(path); err != nil {
+			return nil, err
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		edited := string(data)
+		if edited == content {
+			return nil, errEditCancelled
+		}
+
+		actions, lineErrors := parseEditorFile(edited, tasks)
+		if len(lineErrors) == 0 {
+			if actions == nil {
+				return nil, errEditCancelled
+			}
+			return actions, nil
+		}
+		content = annotateEditorFile(edited, lineErrors)
+	}
+}
+
+func formatEditorFile(tasks *[]adapters.Task, title string) string {
+	var file strings.Builder
+	if title != "" {
+		fmt.Fprintf(&file, "# %s\n", title)
+	}
+	file.WriteString("# Pick an action for every task, then save and quit.\n")
+	file.WriteString("#\n")
+	file.WriteString("# Actions:\n")
+	file.WriteString("# r, revalidate = keep the task, marking it as reviewed\n")
+	file.WriteString("# v, complete   = complete the task\n")
+	file.WriteString("# d, defer      = move the task to someday/maybe\n")
+	file.WriteString("# x, delete     = delete the task\n")
+	file.WriteString("# i, ignore     = leave the task untouched\n")
+	file.WriteString("#\n")
+	file.WriteString("# Removing a line ignores the task, removing every line or saving the file\n")
+	file.WriteString("# unchanged cancels the purge.\n")
+	file.WriteString("# Do not change the IDs.\n\n")
+
+	for _, task := range *tasks {
+		fmt.Fprintf(&file, "revalidate %s %s | %s | created %s | updated %s\n",
+			task.ID,
+			strings.ReplaceAll(task.Content, "\n", " "),
+			task.Project,
+			task.CreatedDate.Format("2006-01-02"),
+			task.UpdatedDate.Format("2006-01-02"))
+	}
+	return file.String()
+}
+
+// parseEditorFile reads the actions back from the edited file. Tasks without
+// a line are ignored. Errors are keyed by line index. It returns nil actions
+// if no task line is left.
+func parseEditorFile(content string, tasks *[]adapters.Task) ([]adapters.TaskAction, map[int]string) {
+	indexByID := make(map[string]int)
+	for i, task := range *tasks {
+		indexByID[task.ID] = i
+	}
+
+	actions := make([]adapters.TaskAction, len(*tasks))
+	for i := range *tasks {
+		actions[i] = adapters.TaskAction{Task: &(*tasks)[i], Action: adapters.ActionIgnore}
+	}
+
+	lineErrors := make(map[int]string)
+	seen := make(map[string]bool)
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			lineErrors[i] = "expected an action followed by a task ID"
+			continue
+		}
+		action, ok := editorActions[strings.ToLower(fields[0])]
+		if !ok {
+			lineErrors[i] = fmt.Sprintf("unknown action %q", fields[0])
+			continue
+		}
+		index, ok := indexByID[fields[1]]
+		if !ok {
+			lineErrors[i] = fmt.Sprintf("unknown task ID %q, IDs must not be changed", fields[1])
+			continue
+		}
+		if seen[fields[1]] {
+			lineErrors[i] = fmt.Sprintf("task %s appears more than once", fields[1])
+			continue
+		}
+		seen[fields[1]] = true
+		actions[index].Action = action
+	}
+
+	if len(seen) == 0 && len(lineErrors) == 0 {
+		return nil, nil
+	}
+	return actions, lineErrors
+}
+
+// annotateEditorFile puts an error comment above every faulty line, dropping
+// the comments of the previous attempt.
+func annotateEditorFile(content string, lineErrors map[int]string) string {
+	var annotated []string
+	for i, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, editorErrorPrefix) {
+			continue
+		}
+		if message, ok := lineErrors[i]; ok {
+			annotated = append(annotated, editorErrorPrefix+message)
+		}
+		annotated = append(annotated, line)
+	}
+	header := fmt.Sprintf("%s%d lines could not be read, fix them or save without changes to cancel", editorErrorPrefix, len(lineErrors))
+	return header + "\n" + strings.Join(annotated, "\n")
+}
+
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	args := strings.Fields(editor)
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %w", editor, err)
+	}
+	return nil
+}
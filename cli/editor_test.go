@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"errors"
+	"github.com/dormunis/gitd/adapters"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEditTasks(t *testing.T) {
+	tests := []struct {
+		name   string
+		editor string
+		want   []adapters.Action
+		err    error
+	}{
+		{"saved unchanged", "true", nil, errEditCancelled},
+		{"every line removed", "sed -i /^revalidate/d", nil, errEditCancelled},
+		{"actions picked", `sed -i -e s/^revalidate\(.1\)/complete\1/ -e s/^revalidate\(.2\)/delete\1/`, []adapters.Action{adapters.ActionComplete, adapters.ActionDelete}, nil},
+	}
+	for _, tt := range tests {
+		t.Setenv("VISUAL", tt.editor)
+		tasks := []adapters.Task{{ID: "1", Content: "Renew passport"}, {ID: "2", Content: "Call the plumber"}}
+		actions, err := editTasks(&tasks, "Purge")
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		if len(actions) != len(tt.want) {
+			t.Errorf("%s: got %d actions, want %d", tt.name, len(actions), len(tt.want))
+			continue
+		}
+		for i, action := range actions {
+			if action.Action != tt.want[i] {
+				t.Errorf("%s: action %d is %v, want %v", tt.name, i, action.Action, tt.want[i])
+			}
+		}
+	}
+}
+
+// TestEditTasksReopensWithErrors edits with sed -i, which saves by renaming a
+// new file over the old one, and checks the second pass shows the errors.
+func TestEditTasksReopensWithErrors(t *testing.T) {
+	dir := t.TempDir()
+	secondPass := filepath.Join(dir, "second-pass")
+	script := filepath.Join(dir, "editor")
+	passes := filepath.Join(dir, "passes")
+	err := os.WriteFile(script, []byte(`#!/bin/sh
+echo >> `+passes+`
+if [ "$(wc -l < `+passes+`)" -gt 2 ]; then
+	exit 1
+fi
+if grep -q '^`+editorErrorPrefix+`' "$1"; then
+	cp "$1" `+secondPass+`
+	sed -i -e '/^# error: /d' -e 's/^bogus /complete /' "$1"
+else
+	sed -i 's/^revalidate 1 /bogus 1 /' "$1"
+fi
+`), 0o700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("VISUAL", script)
+
+	tasks := []adapters.Task{{ID: "1", Content: "Renew passport"}, {ID: "2", Content: "Call the plumber"}}
+	actions, err := editTasks(&tasks, "Purge")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := os.ReadFile(secondPass)
+	if err != nil {
+		t.Fatalf("the file was not opened again: %v", err)
+	}
+	if !strings.Contains(string(reopened), "bogus 1 ") || !strings.Contains(string(reopened), editorErrorPrefix) {
+		t.Errorf("second pass shows\n%s\nwant the last save with error comments", reopened)
+	}
+	want := []adapters.Action{adapters.ActionComplete, adapters.ActionRevalidate}
+	if len(actions) != len(want) {
+		t.Fatalf("got %d actions, want %d", len(actions), len(want))
+	}
+	for i, action := range actions {
+		if action.Action != want[i] {
+			t.Errorf("action %d is %v, want %v", i, action.Action, want[i])
+		}
+	}
+}
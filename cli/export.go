@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"github.com/dormunis/gitd/adapters"
+	"github.com/dormunis/gitd/formats"
+	"github.com/dormunis/gitd/taskmanagers/taskmanager"
+	"io"
+	"os"
+	"time"
+)
+
+// Export writes the filtered tasks to out, or to stdout when out is empty.
+// With completedSince, tasks completed within that timespan are exported too.
+func Export(taskManager adapters.TaskManagerAdapter, filterRequest adapters.FilterRequest, format formats.Format, completedSince *adapters.TimeSpan, out string) error {
+	tasks, err := taskManager.FetchTasks()
+	if err != nil {
+		return err
+	}
+	if completedSince != nil {
+		now := time.Now()
+		completed, err := taskManager.FetchCompletedTasks(completedSince.ModifyDate(now, false), now)
+		if err != nil {
+			return err
+		}
+		tasks = append(tasks, completed...)
+	}
+
+	filteredTasks, err := taskmanager.FilterTasks(&tasks, &filterRequest)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if out != "" {
+		file, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	return formats.Export(w, format, &filteredTasks)
+}
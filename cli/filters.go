@@ -0,0 +1,293 @@
+package cli
+
+import (
+	"github.com/dormunis/gitd/adapters"
+	"github.com/dormunis/gitd/query"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func addFilterFlags(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+	flags.StringSlice("project", nil, "only tasks in projects matching these glob patterns")
+	flags.StringSlice("status", nil, "only tasks with one of these statuses (active, completed, deleted, next, someday, waiting)")
+	flags.StringSlice("exclude-status", nil, "skip tasks with one of these statuses")
+	flags.String("min-urgency", "", "only tasks at least as urgent as this priority (critical, high, medium, low or 1-4, 1 being critical)")
+	flags.String("max-urgency", "", "only tasks at most as urgent as this priority (critical, high, medium, low or 1-4, 1 being critical)")
+	flags.String("min-priority", "", "only tasks with a priority value of at least this, so at most as urgent (1=critical, 4=low)")
+	flags.String("max-priority", "", "only tasks with a priority value of at most this, so at least as urgent (1=critical, 4=low)")
+	flags.MarkDeprecated("min-priority", "use --max-urgency instead")
+	flags.MarkDeprecated("max-priority", "use --min-urgency instead")
+	flags.StringSlice("tag", nil, "only tasks with any of these tags")
+	flags.StringSlice("all-tags", nil, "only tasks with all of these tags")
+	flags.StringSlice("no-tag", nil, "skip tasks with any of these tags")
+	flags.String("created-after", "", "only tasks created within this timespan (e.g. \"2 weeks\")")
+	flags.String("created-before", "", "only tasks created before this timespan")
+	flags.String("updated-after", "", "only tasks updated within this timespan (e.g. \"2 weeks\")")
+	flags.String("updated-before", "", "only tasks not updated within this timespan")
+	flags.String("since", "", "only tasks updated on or after this date (e.g. 2026-01-01, \"last friday\", \"start of quarter\")")
+	flags.String("until", "", "only tasks not updated since this date")
+	flags.String("filter", "", "filter expression, e.g. 'project:Work and (tag:waiting or priority<=2)'")
+	flags.String("view", "", "named view from the config file to select, sort and display tasks")
+}
+
+func addPurgeFlags(cmd *cobra.Command) {
+	addFilterFlags(cmd)
+	cmd.PersistentFlags().String("timespan", "1 month", "only tasks not updated within this timespan")
+	cmd.Flags().Bool("editor", false, "pick the actions in $EDITOR instead of the interactive table")
+	cmd.Flags().String("action", "", "apply this action to every filtered task without the interactive table (complete, defer, delete, revalidate)")
+	cmd.Flags().String("plan", "", "apply the actions of a JSON file mapping task IDs to actions")
+	cmd.Flags().BoolP("yes", "y", false, "apply without asking for confirmation")
+	cmd.Flags().Bool("dry-run", false, "print the commands that would be sent instead of sending them")
+	cmd.Flags().String("out", "", "save the commands to a plan file for gitd apply instead of sending them")
+}
+
+// viewFlag looks up the view named by --view in the settings. Without the flag
+// an empty view is returned, which means default sorting and columns.
+func viewFlag(cmd *cobra.Command) (*adapters.ViewConfig, error) {
+	name, err := cmd.Flags().GetString("view")
+	if err != nil || name == "" {
+		return &adapters.ViewConfig{}, err
+	}
+	view, ok := settings.Views[name]
+	if !ok {
+		return nil, adapters.Errorf(adapters.ErrorConfig, "unknown view: %s", name)
+	}
+	return &view, nil
+}
+
+// buildFilterRequest translates the flags registered by addFilterFlags into a
+// FilterRequest. staleFlag names a flag holding a timespan tasks must not have
+// been updated within, like --updated-before but with a default of its own
+// that is left out when --view or --filter selects the tasks instead.
+// Dates are resolved in the task manager's timezone when it provides one.
+func buildFilterRequest(cmd *cobra.Command, taskManager adapters.TaskManagerAdapter, staleFlag string) (*adapters.FilterRequest, error) {
+	flags := cmd.Flags()
+	fr := adapters.FilterRequest{}
+
+	projects, err := flags.GetStringSlice("project")
+	if err != nil {
+		return nil, err
+	}
+	if len(projects) > 0 {
+		fr.Projects = &projects
+	}
+
+	if fr.Statuses, err = statusesFlag(cmd, "status"); err != nil {
+		return nil, err
+	}
+	if fr.ExcludedStatuses, err = statusesFlag(cmd, "exclude-status"); err != nil {
+		return nil, err
+	}
+	if fr.MinPriority, err = priorityFlag(cmd, "min-priority"); err != nil {
+		return nil, err
+	}
+	if fr.MaxPriority, err = priorityFlag(cmd, "max-priority"); err != nil {
+		return nil, err
+	}
+	// Priority values grow as urgency drops, so the most urgent priority
+	// allowed is the lowest value.
+	if urgency, err := priorityFlag(cmd, "min-urgency"); err != nil {
+		return nil, err
+	} else if urgency != nil {
+		fr.MaxPriority = urgency
+	}
+	if urgency, err := priorityFlag(cmd, "max-urgency"); err != nil {
+		return nil, err
+	} else if urgency != nil {
+		fr.MinPriority = urgency
+	}
+
+	if fr.AnyTags, err = tagsFlag(cmd, "tag"); err != nil {
+		return nil, err
+	}
+	if fr.AllTags, err = tagsFlag(cmd, "all-tags"); err != nil {
+		return nil, err
+	}
+	if fr.NoTags, err = tagsFlag(cmd, "no-tag"); err != nil {
+		return nil, err
+	}
+
+	if fr.CreatedAfter, err = timeSpanFlag(cmd, "created-after"); err != nil {
+		return nil, err
+	}
+	if fr.CreatedBefore, err = timeSpanFlag(cmd, "created-before"); err != nil {
+		return nil, err
+	}
+	if fr.UpdatedAfter, err = timeSpanFlag(cmd, "updated-after"); err != nil {
+		return nil, err
+	}
+	if fr.UpdatedBefore, err = timeSpanFlag(cmd, "updated-before"); err != nil {
+		return nil, err
+	}
+	// A view or filter expression replaces the default staleness, unless the
+	// timespan was given explicitly.
+	explicit := flags.Changed(staleFlag) || !(flags.Changed("view") || flags.Changed("filter"))
+	if staleFlag != "" && fr.UpdatedBefore == nil && explicit {
+		if fr.UpdatedBefore, err = timeSpanFlag(cmd, staleFlag); err != nil {
+			return nil, err
+		}
+	}
+
+	since, err := flags.GetString("since")
+	if err != nil {
+		return nil, err
+	}
+	until, err := flags.GetString("until")
+	if err != nil {
+		return nil, err
+	}
+	if since != "" || until != "" {
+		now, err := userNow(taskManager)
+		if err != nil {
+			return nil, err
+		}
+		fr.Location = now.Location()
+		if fr.UpdatedSince, err = parseDateFlag("since", since, now); err != nil {
+			return nil, err
+		}
+		if fr.UpdatedUntil, err = parseDateFlag("until", until, now); err != nil {
+			return nil, err
+		}
+	}
+
+	view, err := viewFlag(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if view.Query != "" {
+		expr, err := query.Parse(view.Query)
+		if err != nil {
+			return nil, adapters.Errorf(adapters.ErrorConfig, "invalid query in view: %w", err)
+		}
+		fr.Query = expr
+	}
+
+	filter, err := flags.GetString("filter")
+	if err != nil {
+		return nil, err
+	}
+	if filter != "" {
+		expr, err := query.Parse(filter)
+		if err != nil {
+			return nil, adapters.Errorf(adapters.ErrorValidation, "invalid --filter: %w", err)
+		}
+		fr.Query = query.And(fr.Query, expr)
+	}
+	if fr.Query != nil && fr.Location == nil {
+		now, err := userNow(taskManager)
+		if err != nil {
+			return nil, err
+		}
+		fr.Location = now.Location()
+	}
+
+	return &fr, nil
+}
+
+func statusesFlag(cmd *cobra.Command, name string) (*[]adapters.Status, error) {
+	values, err := cmd.Flags().GetStringSlice(name)
+	if err != nil || len(values) == 0 {
+		return nil, err
+	}
+	statuses := make([]adapters.Status, 0, len(values))
+	for _, value := range values {
+		status, err := adapters.ParseStatus(value)
+		if err != nil {
+			return nil, adapters.Errorf(adapters.ErrorValidation, "invalid --%s: %w", name, err)
+		}
+		statuses = append(statuses, status)
+	}
+	return &statuses, nil
+}
+
+func priorityFlag(cmd *cobra.Command, name string) (*adapters.Priority, error) {
+	value, err := cmd.Flags().GetString(name)
+	if err != nil || value == "" {
+		return nil, err
+	}
+	priority, err := adapters.ParsePriority(value)
+	if err != nil {
+		return nil, adapters.Errorf(adapters.ErrorValidation, "invalid --%s: %w", name, err)
+	}
+	return &priority, nil
+}
+
+func tagsFlag(cmd *cobra.Command, name string) (*[]string, error) {
+	values, err := cmd.Flags().GetStringSlice(name)
+	if err != nil || len(values) == 0 {
+		return nil, err
+	}
+	return &values, nil
+}
+
+func timeSpanFlag(cmd *cobra.Command, name string) (*adapters.TimeSpan, error) {
+	value, err := cmd.Flags().GetString(name)
+	if err != nil || value == "" {
+		return nil, err
+	}
+	timespan, err := adapters.NewTimeSpan(value)
+	if err != nil {
+		return nil, adapters.Errorf(adapters.ErrorValidation, "invalid --%s %s: %w", name, value, err)
+	}
+	return timespan, nil
+}
+
+func parseDateFlag(name string, value string, now time.Time) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := adapters.ParseDate(value, now)
+	if err != nil {
+		return nil, adapters.Errorf(adapters.ErrorValidation, "invalid --%s: %w", name, err)
+	}
+	return &date, nil
+}
+
+// userNow returns the current time in the task manager user's timezone.
+func userNow(taskManager adapters.TaskManagerAdapter) (time.Time, error) {
+	if provider, ok := taskManager.(adapters.TimezoneProvider); ok {
+		location, err := provider.Location()
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Now().In(location), nil
+	}
+	return time.Now(), nil
+}
+
+func purgeOptions(cmd *cobra.Command) (*PurgeOptions, error) {
+	options := &PurgeOptions{}
+	options.Editor, _ = cmd.Flags().GetBool("editor")
+	options.PlanFile, _ = cmd.Flags().GetString("plan")
+	options.Yes, _ = cmd.Flags().GetBool("yes")
+	options.DryRun, _ = cmd.Flags().GetBool("dry-run")
+	options.Out, _ = cmd.Flags().GetString("out")
+
+	actionName, _ := cmd.Flags().GetString("action")
+	if actionName != "" {
+		action, err := adapters.ParseAction(actionName)
+		if err != nil {
+			return nil, adapters.NewError(adapters.ErrorValidation, err)
+		}
+		options.Action = &action
+	}
+
+	modes := 0
+	for _, set := range []bool{options.Editor, options.Action != nil, options.PlanFile != ""} {
+		if set {
+			modes++
+		}
+	}
+	if modes > 1 {
+		return nil, adapters.Errorf(adapters.ErrorValidation, "--editor, --action and --plan cannot be combined")
+	}
+
+	// An unattended purge must not fall back on the default selection.
+	destructive := options.Action != nil && (*options.Action == adapters.ActionComplete || *options.Action == adapters.ActionDelete)
+	if destructive && options.Yes && !cmd.Flags().Changed("filter") && !cmd.Flags().Changed("view") && !cmd.Flags().Changed("timespan") {
+		return nil, adapters.Errorf(adapters.ErrorValidation, "--action %s --yes needs an explicit --filter, --view or --timespan", *options.Action)
+	}
+	return options, nil
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"github.com/dormunis/gitd/adapters"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func newPurgeCommand(t *testing.T, args ...string) *cobra.Command {
+	cmd := &cobra.Command{Use: "purge"}
+	addPurgeFlags(cmd)
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatal(err)
+	}
+	return cmd
+}
+
+func TestBuildFilterRequestStaleTimeSpan(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"default", nil, "1 month"},
+		{"timespan", []string{"--timespan", "2 weeks"}, "2 weeks"},
+		{"updated before wins", []string{"--timespan", "2 weeks", "--updated-before", "3 days"}, "3 days"},
+		{"filter", []string{"--filter", "updated>30d"}, ""},
+		{"view", []string{"--view", "stale-work"}, ""},
+		{"view and timespan", []string{"--view", "stale-work", "--timespan", "2 weeks"}, "2 weeks"},
+	}
+	settings.Views = map[string]adapters.ViewConfig{"stale-work": {Query: "updated>30d"}}
+	t.Cleanup(func() { settings.Views = nil })
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fr, err := buildFilterRequest(newPurgeCommand(t, tt.args...), nil, "timespan")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fr.UpdatedAfter != nil {
+				t.Errorf("got UpdatedAfter %v, want none", fr.UpdatedAfter)
+			}
+			if tt.want == "" {
+				if fr.UpdatedBefore != nil {
+					t.Errorf("got UpdatedBefore %v, want none", fr.UpdatedBefore)
+				}
+				return
+			}
+			want, _ := adapters.NewTimeSpan(tt.want)
+			if fr.UpdatedBefore == nil || *fr.UpdatedBefore != *want {
+				t.Errorf("got UpdatedBefore %v, want %v", fr.UpdatedBefore, want)
+			}
+		})
+	}
+}
+
+func TestBuildFilterRequestWindows(t *testing.T) {
+	span := func(value string) *adapters.TimeSpan {
+		timespan, _ := adapters.NewTimeSpan(value)
+		return timespan
+	}
+	priority := func(priority adapters.Priority) *adapters.Priority { return &priority }
+	tests := []struct {
+		name string
+		args []string
+		want adapters.FilterRequest
+	}{
+		{"updated after", []string{"--updated-after", "2 weeks"}, adapters.FilterRequest{UpdatedAfter: span("2 weeks")}},
+		{"updated window", []string{"--updated-after", "3 months", "--updated-before", "1 month"},
+			adapters.FilterRequest{UpdatedAfter: span("3 months"), UpdatedBefore: span("1 month")}},
+		{"created window", []string{"--created-after", "1 year", "--created-before", "6 months"},
+			adapters.FilterRequest{CreatedAfter: span("1 year"), CreatedBefore: span("6 months")}},
+		{"min urgency", []string{"--min-urgency", "high"}, adapters.FilterRequest{MaxPriority: priority(adapters.PriorityHigh)}},
+		{"max urgency", []string{"--max-urgency", "2"}, adapters.FilterRequest{MinPriority: priority(adapters.PriorityHigh)}},
+		{"deprecated priority flags", []string{"--min-priority", "3", "--max-priority", "critical"},
+			adapters.FilterRequest{MinPriority: priority(adapters.PriorityMedium), MaxPriority: priority(adapters.PriorityCritical)}},
+	}
+	equal := func(a, b *adapters.TimeSpan) bool { return (a == nil) == (b == nil) && (a == nil || *a == *b) }
+	equalPriority := func(a, b *adapters.Priority) bool { return (a == nil) == (b == nil) && (a == nil || *a == *b) }
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{Use: "list"}
+			addFilterFlags(cmd)
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			fr, err := buildFilterRequest(cmd, nil, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !equal(fr.UpdatedAfter, tt.want.UpdatedAfter) || !equal(fr.UpdatedBefore, tt.want.UpdatedBefore) ||
+				!equal(fr.CreatedAfter, tt.want.CreatedAfter) || !equal(fr.CreatedBefore, tt.want.CreatedBefore) {
+				t.Errorf("got updated %v-%v, created %v-%v, want updated %v-%v, created %v-%v",
+					fr.UpdatedAfter, fr.UpdatedBefore, fr.CreatedAfter, fr.CreatedBefore,
+					tt.want.UpdatedAfter, tt.want.UpdatedBefore, tt.want.CreatedAfter, tt.want.CreatedBefore)
+			}
+			if !equalPriority(fr.MinPriority, tt.want.MinPriority) || !equalPriority(fr.MaxPriority, tt.want.MaxPriority) {
+				t.Errorf("got priorities %v-%v, want %v-%v", fr.MinPriority, fr.MaxPriority, tt.want.MinPriority, tt.want.MaxPriority)
+			}
+		})
+	}
+}
+
+func TestPurgeOptionsUnattended(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{"delete default selection", []string{"--action", "delete", "--yes"}, true},
+		{"complete default selection", []string{"--action", "complete", "-y"}, true},
+		{"delete with timespan", []string{"--action", "delete", "--yes", "--timespan", "1 year"}, false},
+		{"delete with filter", []string{"--action", "delete", "--yes", "--filter", "tag:old"}, false},
+		{"delete asks", []string{"--action", "delete"}, false},
+		{"ignore default selection", []string{"--action", "ignore", "--yes"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := purgeOptions(newPurgeCommand(t, tt.args...))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && adapters.KindOf(err) != adapters.ErrorValidation {
+				t.Errorf("got kind %v, want validation", adapters.KindOf(err))
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"github.com/dormunis/gitd/formats"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+type ImportOptions struct {
+	Format          formats.Format
+	Mapping         map[string]string // field to column, see formats.Import
+	DryRun          bool              // preview without creating anything
+	Yes             bool              // skip the confirmation
+	AllowDuplicates bool              // import tasks that already exist
+}
+
+type importRow struct {
+	Record     formats.Record
+	Skip       string // why the task is not imported, empty when it is
+	NewProject bool
+}
+
+// Import creates the tasks of a file, along with the projects and labels they
+// refer to. Completed tasks are skipped, and so are tasks whose content already
+// exists, in the task manager or as a pending task earlier in the file.
+func Import(taskManager adapters.TaskManagerAdapter, path string, options ImportOptions) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return adapters.NewError(adapters.ErrorValidation, err)
+	}
+	defer file.Close()
+	records, err := formats.Import(file, options.Format, options.Mapping)
+	if err != nil {
+		return adapters.Errorf(adapters.ErrorValidation, "%s: %w", path, err)
+	}
+	if len(records) == 0 {
+		return adapters.Errorf(adapters.ErrorValidation, "no tasks found in %s", path)
+	}
+
+	tasks, err := taskManager.FetchTasks()
+	if err != nil {
+		return err
+	}
+	projects, err := taskManager.FetchProjects()
+	if err != nil {
+		return err
+	}
+	labelCreator, canCreateLabels := taskManager.(adapters.LabelCreator)
+	var labels []string
+	if canCreateLabels {
+		if labels, err = labelCreator.FetchLabels(); err != nil {
+			return err
+		}
+	}
+
+	existing := make(map[string]bool)
+	for _, task := range tasks {
+		existing[importKey(task.Content)] = true
+	}
+	projectIDs := make(map[string]string)
+	for _, project := range projects {
+		if !project.Archived && !project.Inbox {
+			projectIDs[importKey(project.Name)] = project.ID
+		}
+	}
+
+	var rows []importRow
+	var newProjects, newLabels []string
+	for _, record := range records {
+		row := importRow{Record: record}
+		key := importKey(record.Content)
+		switch {
+		case record.Completed:
+			row.Skip = "completed"
+		case existing[key] && !options.AllowDuplicates:
+			row.Skip = "duplicate"
+		}
+		if !record.Completed {
+			existing[key] = true
+		}
+		if row.Skip == "" {
+			if _, ok := projectIDs[importKey(record.Project)]; !ok && record.Project != "" && !isInboxName(projects, record.Project) {
+				row.NewProject = true
+				if !slices.ContainsFunc(newProjects, func(name string) bool { return importKey(name) == importKey(record.Project) }) {
+					newProjects = append(newProjects, record.Project)
+				}
+			}
+			if canCreateLabels {
+				row.Record.Tags = slices.Clone(record.Tags)
+				for i, tag := range record.Tags {
+					index := slices.IndexFunc(labels, func(label string) bool { return strings.EqualFold(label, tag) })
+					if index == -1 {
+						labels = append(labels, tag)
+						newLabels = append(newLabels, tag)
+					} else {
+						row.Record.Tags[i] = labels[index] // as spelled in the task manager
+					}
+				}
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	printImport(rows, newProjects, newLabels)
+	count := 0
+	for _, row := range rows {
+		if row.Skip == "" {
+			count++
+		}
+	}
+	if options.DryRun || count == 0 {
+		return nil
+	}
+
+	projectCreator, canCreateProjects := taskManager.(adapters.ProjectCreator)
+	if len(newProjects) > 0 && !canCreateProjects {
+		return adapters.Errorf(adapters.ErrorValidation, "this task manager cannot create projects, missing: %s", strings.Join(newProjects, ", "))
+	}
+	if !options.Yes {
+		fmt.Printf("Are you sure you want to import %d tasks? (y/n): ", count)
+		var response string
+		fmt.Scanln(&response)
+		if strings.ToLower(response) != "y" {
+			return adapters.ErrAborted
+		}
+	}
+
+	var created []string // what was created before the tasks, for errors
+	if len(newLabels) > 0 {
+		if err := labelCreator.CreateLabels(newLabels); err != nil {
+			return err
+		}
+		created = append(created, "labels "+strings.Join(newLabels, ", "))
+	}
+	if len(newProjects) > 0 {
+		createdProjects, err := projectCreator.CreateProjects(newProjects)
+		if err != nil {
+			return importError(err, created)
+		}
+		for _, project := range createdProjects {
+			projectIDs[importKey(project.Name)] = project.ID
+		}
+		created = append(created, "projects "+strings.Join(newProjects, ", "))
+	}
+
+	drafts := make([]adapters.TaskDraft, 0, count)
+	for _, row := range rows {
+		if row.Skip == "" {
+			drafts = append(drafts, importDraft(row.Record, projectIDs))
+		}
+	}
+	if err := taskManager.CreateTasks(&drafts); err != nil {
+		return importError(err, created)
+	}
+	fmt.Printf("Imported %d tasks\n", len(drafts))
+	return nil
+}
+
+// importError names the labels and projects created before an import failed,
+// as they are left behind. Importing again reuses them.
+func importError(err error, created []string) error {
+	if len(created) == 0 {
+		return err
+	}
+	return fmt.Errorf("%w (already created %s)", err, strings.Join(created, " and "))
+}
+
+// importDraft appends the notes to the description, as drafts have none.
+func importDraft(record formats.Record, projectIDs map[string]string) adapters.TaskDraft {
+	description := strings.TrimSpace(strings.Join(append([]string{record.Description}, record.Notes...), "\n\n"))
+	draft := adapters.TaskDraft{
+		Content:     record.Content,
+		Description: description,
+		ProjectID:   projectIDs[importKey(record.Project)],
+		Tags:        record.Tags,
+		Priority:    record.Priority,
+	}
+	if record.Due != "" {
+		due := record.Due
+		draft.Due = &due
+	}
+	return draft
+}
+
+func printImport(rows []importRow, newProjects, newLabels []string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LINE\tACTION\tTASK\tPROJECT\tLABELS\tDUE")
+	skipped := make(map[string]int)
+	for _, row := range rows {
+		action := "create"
+		if row.Skip != "" {
+			action = "skip (" + row.Skip + ")"
+			skipped[row.Skip]++
+		}
+		project := row.Record.Project
+		if project == "" {
+			project = "Inbox"
+		} else if row.NewProject {
+			project += " (new)"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", row.Record.Line, action, row.Record.Content, project,
+			strings.Join(row.Record.Tags, ", "), row.Record.Due)
+	}
+	w.Flush()
+
+	fmt.Println()
+	fmt.Printf("%d tasks to create, %d duplicates and %d completed tasks skipped\n",
+		len(rows)-skipped["duplicate"]-skipped["completed"], skipped["duplicate"], skipped["completed"])
+	if len(newProjects) > 0 {
+		fmt.Printf("New projects: %s\n", strings.Join(newProjects, ", "))
+	}
+	if len(newLabels) > 0 {
+		fmt.Printf("New labels: %s\n", strings.Join(newLabels, ", "))
+	}
+}
+
+// importKey compares names and contents regardless of case, spacing and the
+// underscores todo.txt uses for spaces.
+func importKey(value string) string {
+	return strings.ToLower(strings.Join(strings.Fields(strings.ReplaceAll(value, "_", " ")), " "))
+}
+
+func isInboxName(projects []adapters.Project, name string) bool {
+	return slices.ContainsFunc(projects, func(project adapters.Project) bool {
+		return project.Inbox && importKey(project.Name) == importKey(name)
+	})
+}
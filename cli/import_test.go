@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"github.com/dormunis/gitd/adapters"
+	"github.com/dormunis/gitd/formats"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// failingTaskManager cannot create tasks.
+type failingTaskManager struct {
+	*memoryTaskManager
+}
+
+func (f *failingTaskManager) CreateTasks(*[]adapters.TaskDraft) error {
+	return adapters.Errorf(adapters.ErrorNetwork, "connection reset")
+}
+
+func TestImport(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		fail     bool
+		existing []string
+		want     []string
+		err      string
+	}{
+		{"completed task before a pending one", "x 2026-01-02 Renew passport\nRenew passport +Personal\n", false, nil, []string{"Renew passport"}, ""},
+		{"duplicate of an existing task", "Renew passport\nCall the plumber\n", false, []string{"Renew passport"}, []string{"Renew passport", "Call the plumber"}, ""},
+		{"failure after creating projects", "Plant tomatoes +Garden\n", true, nil, nil, "already created projects Garden"},
+		{"failure without creating anything", "Renew passport\n", true, nil, nil, "connection reset"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "todo.txt")
+		if err := os.WriteFile(path, []byte(tt.file), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		memory := newMemoryTaskManager("memory", time.Now())
+		for _, content := range tt.existing {
+			memory.add(content, "")
+		}
+		var taskManager adapters.TaskManagerAdapter = memory
+		if tt.fail {
+			taskManager = &failingTaskManager{memory}
+		}
+
+		var err error
+		captureStdout(t, func() error {
+			err = Import(taskManager, path, ImportOptions{Format: formats.FormatTodoTxt, Yes: true})
+			return nil
+		})
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) || adapters.KindOf(err) != adapters.ErrorNetwork {
+				t.Errorf("%s: got error %v, want a network error with %q", tt.name, err, tt.err)
+			}
+			if tt.err == "connection reset" && strings.Contains(err.Error(), "already created") {
+				t.Errorf("%s: got error %v, want nothing reported as created", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var got []string
+		for _, task := range memory.tasks {
+			got = append(got, task.Content)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: got tasks %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
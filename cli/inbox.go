@@ -0,0 +1,414 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type inboxPrompt int
+
+const (
+	promptNone inboxPrompt = iota
+	promptProject
+	promptLabels
+	promptDue
+	promptSubtasks
+)
+
+type inboxModel struct {
+	tasks    *[]adapters.Task
+	edits    *[]adapters.TaskEdit
+	projects []adapters.Project
+	cursor   int
+	prompt   inboxPrompt
+	input    textinput.Model
+	err      string
+	keys     inboxKeymap
+	help     help.Model
+	aborted  bool
+}
+
+type inboxKeymap struct {
+	Next     key.Binding
+	Previous key.Binding
+	Move     key.Binding
+	Labels   key.Binding
+	Action   key.Binding
+	Someday  key.Binding
+	Due      key.Binding
+	Split    key.Binding
+	Trash    key.Binding
+	Reset    key.Binding
+	Save     key.Binding
+	Quit     key.Binding
+	Help     key.Binding
+}
+
+func (k inboxKeymap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Help, k.Save, k.Quit}
+}
+
+func (k inboxKeymap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Next, k.Previous},
+		{k.Move, k.Labels, k.Action, k.Someday, k.Due, k.Split, k.Trash, k.Reset},
+		{k.Help, k.Save, k.Quit},
+	}
+}
+
+var inboxKeys = inboxKeymap{
+	Next: key.NewBinding(
+		key.WithKeys("enter", "right"),
+		key.WithHelp("enter/→", "next task"),
+	),
+	Previous: key.NewBinding(
+		key.WithKeys("left"),
+		key.WithHelp("←", "previous task"),
+	),
+	Move: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "move to project"),
+	),
+	Labels: key.NewBinding(
+		key.WithKeys("l"),
+		key.WithHelp("l", "add labels/contexts"),
+	),
+	Action: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "mark next"),
+	),
+	Someday: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "mark someday"),
+	),
+	Due: key.NewBinding(
+		key.WithKeys("u"),
+		key.WithHelp("u", "set due date"),
+	),
+	Split: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "split into subtasks"),
+	),
+	Trash: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "trash"),
+	),
+	Reset: key.NewBinding(
+		key.WithKeys("backspace", "delete"),
+		key.WithHelp("backspace/delete", "undo changes to task"),
+	),
+	Save: key.NewBinding(
+		key.WithKeys("esc", "q"),
+		key.WithHelp("esc/q", "save and quit"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys("ctrl+c"),
+		key.WithHelp("ctrl+c", "quit without saving"),
+	),
+	Help: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "toggle help"),
+	),
+}
+
+// Inbox walks through the tasks in the inbox one at a time to clarify them,
+// then sends all changes as a single batch.
+func Inbox(taskManager adapters.TaskManagerAdapter) error {
+	tasks, err := taskManager.FetchTasks()
+	if err != nil {
+		return err
+	}
+	projects, err := taskManager.FetchProjects()
+	if err != nil {
+		return err
+	}
+
+	inbox, err := selectInbox(&tasks)
+	if err != nil {
+		return err
+	}
+	if len(inbox) == 0 {
+		fmt.Println("Inbox is empty")
+		return nil
+	}
+
+	edits := make([]adapters.TaskEdit, len(inbox))
+	for i := range inbox {
+		edits[i].Task = &inbox[i]
+	}
+
+	input := textinput.New()
+	input.CharLimit = 500
+	programModel := inboxModel{
+		tasks:    &inbox,
+		edits:    &edits,
+		projects: projects,
+		input:    input,
+		keys:     inboxKeys,
+		help:     help.New(),
+	}
+
+	p := tea.NewProgram(programModel, tea.WithAltScreen())
+	finalModel, err := p.Run()
+	if err != nil {
+		return fmt.Errorf("could not run program: %w", err)
+	}
+	if finalModel.(inboxModel).aborted {
+		return adapters.ErrAborted
+	}
+
+	var changed []adapters.TaskEdit
+	for _, edit := range edits {
+		if !edit.IsEmpty() {
+			changed = append(changed, edit)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	fmt.Printf("You are about to update %d of %d inbox items\n", len(changed), len(inbox))
+	fmt.Printf("Are you sure you want to continue? (y/n): ")
+	var response string
+	fmt.Scanln(&response)
+	if strings.ToLower(response) != "y" {
+		return adapters.ErrAborted
+	}
+	return editJournaled(taskManager, &changed)
+}
+
+func (m inboxModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m inboxModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	if m.prompt != promptNone {
+		return m.updatePrompt(keyMsg)
+	}
+
+	edit := &(*m.edits)[m.cursor]
+	m.err = ""
+	switch {
+	case key.Matches(keyMsg, m.keys.Next):
+		m.advance()
+	case key.Matches(keyMsg, m.keys.Previous):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case key.Matches(keyMsg, m.keys.Move):
+		return m.startPrompt(promptProject, "project name")
+	case key.Matches(keyMsg, m.keys.Labels):
+		return m.startPrompt(promptLabels, "@phone, errands")
+	case key.Matches(keyMsg, m.keys.Due):
+		return m.startPrompt(promptDue, "friday, every monday, 2026-01-01")
+	case key.Matches(keyMsg, m.keys.Split):
+		return m.startPrompt(promptSubtasks, "first subtask; second subtask")
+	case key.Matches(keyMsg, m.keys.Action):
+		status := adapters.StatusNext
+		edit.Status = &status
+		m.advance()
+	case key.Matches(keyMsg, m.keys.Someday):
+		status := adapters.StatusSomeday
+		edit.Status = &status
+		m.advance()
+	case key.Matches(keyMsg, m.keys.Trash):
+		edit.Delete = !edit.Delete
+		if edit.Delete {
+			m.advance()
+		}
+	case key.Matches(keyMsg, m.keys.Reset):
+		*edit = adapters.TaskEdit{Task: edit.Task}
+	case key.Matches(keyMsg, m.keys.Save):
+		return m, tea.Quit
+	case key.Matches(keyMsg, m.keys.Quit):
+		m.aborted = true
+		return m, tea.Quit
+	case key.Matches(keyMsg, m.keys.Help):
+		m.help.ShowAll = !m.help.ShowAll
+	}
+	return m, nil
+}
+
+func (m *inboxModel) advance() {
+	if m.cursor < len(*m.tasks)-1 {
+		m.cursor++
+	}
+}
+
+func (m inboxModel) startPrompt(prompt inboxPrompt, placeholder string) (tea.Model, tea.Cmd) {
+	m.prompt = prompt
+	m.input.Reset()
+	m.input.Placeholder = placeholder
+	return m, m.input.Focus()
+}
+
+func (m inboxModel) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.prompt = promptNone
+		m.input.Blur()
+		return m, nil
+	case tea.KeyEnter:
+		if err := m.applyPrompt(strings.TrimSpace(m.input.Value())); err != nil {
+			m.err = err.Error()
+			return m, nil
+		}
+		m.err = ""
+		m.prompt = promptNone
+		m.input.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *inboxModel) applyPrompt(value string) error {
+	edit := &(*m.edits)[m.cursor]
+	if value == "" {
+		return nil
+	}
+	switch m.prompt {
+	case promptProject:
+		project, err := findProject(m.projects, value)
+		if err != nil {
+			return err
+		}
+		edit.ProjectID = &project.ID
+	case promptLabels:
+		for _, label := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+			edit.AddTags = appendUnique(edit.AddTags, strings.TrimPrefix(label, "@"))
+		}
+	case promptDue:
+		edit.Due = &value
+	case promptSubtasks:
+		for _, subtask := range strings.Split(value, ";") {
+			if subtask = strings.TrimSpace(subtask); subtask != "" {
+				edit.Subtasks = append(edit.Subtasks, subtask)
+			}
+		}
+	}
+	return nil
+}
+
+// findProject matches a project by its name, case-insensitively, falling back
+// to a unique prefix.
+func findProject(projects []adapters.Project, name string) (*adapters.Project, error) {
+	var candidates []*adapters.Project
+	for i := range projects {
+		if strings.EqualFold(projects[i].Name, name) {
+			return &projects[i], nil
+		}
+		if strings.HasPrefix(strings.ToLower(projects[i].Name), strings.ToLower(name)) {
+			candidates = append(candidates, &projects[i])
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return nil, fmt.Errorf("unknown project: %s", name)
+	case 1:
+		return candidates[0], nil
+	default:
+		names := make([]string, len(candidates))
+		for i, candidate := range candidates {
+			names[i] = candidate.Name
+		}
+		return nil, fmt.Errorf("ambiguous project %s: %s", name, strings.Join(names, ", "))
+	}
+}
+
+var (
+	inboxTaskStyle    = lipgloss.NewStyle().Bold(true).MarginTop(1)
+	inboxDetailStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	inboxChangeStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	inboxErrorStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	inboxPromptTitles = map[inboxPrompt]string{
+		promptProject:  "Move to project: ",
+		promptLabels:   "Add labels: ",
+		promptDue:      "Due: ",
+		promptSubtasks: "Subtasks: ",
+	}
+)
+
+func (m inboxModel) View() string {
+	task := (*m.tasks)[m.cursor]
+	edit := (*m.edits)[m.cursor]
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Inbox %d/%d", m.cursor+1, len(*m.tasks))))
+	b.WriteString("\n")
+	b.WriteString(m.help.View(m.keys))
+	b.WriteString("\n")
+	b.WriteString(inboxTaskStyle.Render(task.Content))
+	b.WriteString("\n")
+	details := fmt.Sprintf("created %s", task.CreatedDate.Format("2006-01-02"))
+	if len(task.Tags) > 0 {
+		details += " · " + strings.Join(task.Tags, ", ")
+	}
+	b.WriteString(inboxDetailStyle.Render(details))
+	b.WriteString("\n\n")
+
+	for _, change := range m.describeEdit(&edit) {
+		b.WriteString(inboxChangeStyle.Render("• "+change) + "\n")
+	}
+
+	if m.prompt != promptNone {
+		b.WriteString("\n" + inboxPromptTitles[m.prompt] + m.input.View() + "\n")
+	}
+	if m.err != "" {
+		b.WriteString(inboxErrorStyle.Render(m.err) + "\n")
+	}
+	return b.String()
+}
+
+func (m inboxModel) describeEdit(edit *adapters.TaskEdit) []string {
+	if edit.Delete {
+		return []string{"trash"}
+	}
+	var changes []string
+	if edit.ProjectID != nil {
+		name := *edit.ProjectID
+		for _, project := range m.projects {
+			if project.ID == *edit.ProjectID {
+				name = project.Name
+			}
+		}
+		changes = append(changes, "move to "+name)
+	}
+	if edit.Status != nil {
+		changes = append(changes, "mark "+edit.Status.String())
+	}
+	if len(edit.AddTags) > 0 {
+		changes = append(changes, "add labels: "+strings.Join(edit.AddTags, ", "))
+	}
+	if len(edit.RemoveTags) > 0 {
+		changes = append(changes, "remove labels: "+strings.Join(edit.RemoveTags, ", "))
+	}
+	if edit.Due != nil {
+		changes = append(changes, "due "+*edit.Due)
+	}
+	for _, subtask := range edit.Subtasks {
+		changes = append(changes, "subtask: "+subtask)
+	}
+	return changes
+}
+
+func appendUnique(values []string, value string) []string {
+	if slices.Contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
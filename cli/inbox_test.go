@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"github.com/dormunis/gitd/adapters"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestInboxMarksStatuses(t *testing.T) {
+	tests := []struct {
+		name string
+		keys string            // < moves back to the previous task
+		want []adapters.Status // zero for no status change
+	}{
+		{"next and someday", "ns", []adapters.Status{adapters.StatusNext, adapters.StatusSomeday}},
+		{"changed mind", "n<sn", []adapters.Status{adapters.StatusSomeday, adapters.StatusNext}},
+		{"untouched", "", []adapters.Status{0, 0}},
+	}
+	for _, tt := range tests {
+		tasks := []adapters.Task{{ID: "1", Content: "Renew passport"}, {ID: "2", Content: "Learn the cello"}}
+		edits := make([]adapters.TaskEdit, len(tasks))
+		for i := range tasks {
+			edits[i].Task = &tasks[i]
+		}
+		var m tea.Model = inboxModel{tasks: &tasks, edits: &edits, keys: inboxKeys}
+		for _, r := range tt.keys {
+			msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}
+			if r == '<' {
+				msg = tea.KeyMsg{Type: tea.KeyLeft}
+			}
+			m, _ = m.Update(msg)
+		}
+
+		for i, edit := range edits {
+			if len(edit.AddTags) > 0 || len(edit.RemoveTags) > 0 {
+				t.Errorf("%s: task %d changes labels %v/%v, want the status left to the task manager", tt.name, i+1, edit.AddTags, edit.RemoveTags)
+			}
+			var got adapters.Status
+			if edit.Status != nil {
+				got = *edit.Status
+			}
+			if got != tt.want[i] {
+				t.Errorf("%s: task %d got status %v, want %v", tt.name, i+1, got, tt.want[i])
+			}
+		}
+	}
+}
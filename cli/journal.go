@@ -0,0 +1,220 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const journalFile = "journal.jsonl"
+
+var undoDescriptions = map[adapters.Action]string{
+	adapters.ActionComplete:   "uncomplete",
+	adapters.ActionDelete:     "recreate",
+	adapters.ActionDefer:      "restore labels of",
+	adapters.ActionRevalidate: "remove revalidation note from",
+}
+
+// applyJournaled journals the actions with a snapshot of their tasks and
+// then runs apply, so that gitd undo can reverse them even if gitd does not
+// get to hear back. Actions the task manager reports as failed are marked so
+// that undo leaves them alone; a run where nothing was applied is dropped.
+func applyJournaled(actions *[]adapters.TaskAction, apply func() error) error {
+	run := adapters.NewJournalRun(actions)
+	if len(run.Entries) == 0 {
+		return apply()
+	}
+	run.Pending = true
+	if err := appendJournal(run); err != nil {
+		return err
+	}
+
+	applyErr := apply()
+	run.Pending = false
+	if applyErr != nil {
+		var partial *adapters.PartialError
+		if errors.As(applyErr, &partial) {
+			run.MarkFailed(partial.Failed)
+		} else {
+			run.MarkFailed(taskIDs(run))
+		}
+	}
+	if err := updateJournal(run); err != nil {
+		return errors.Join(applyErr, err)
+	}
+	return applyErr
+}
+
+// editJournaled applies edits, journaling the ones that complete or delete
+// a task.
+func editJournaled(taskManager adapters.TaskManagerAdapter, edits *[]adapters.TaskEdit) error {
+	var actions []adapters.TaskAction
+	for _, edit := range *edits {
+		switch {
+		case edit.Delete:
+			actions = append(actions, adapters.TaskAction{Task: edit.Task, Action: adapters.ActionDelete})
+		case edit.Complete:
+			actions = append(actions, adapters.TaskAction{Task: edit.Task, Action: adapters.ActionComplete})
+		}
+	}
+	return applyJournaled(&actions, func() error { return taskManager.EditTasks(edits) })
+}
+
+func taskIDs(run *adapters.JournalRun) []string {
+	ids := make([]string, 0, len(run.Entries))
+	for _, entry := range run.Entries {
+		ids = append(ids, entry.Task.ID)
+	}
+	return ids
+}
+
+// updateJournal replaces the journaled run with the same ID, removing it if
+// none of its actions were applied.
+func updateJournal(run *adapters.JournalRun) error {
+	runs, err := loadJournal()
+	if err != nil {
+		return err
+	}
+	updated := runs[:0]
+	for _, existing := range runs {
+		if existing.ID != run.ID {
+			updated = append(updated, existing)
+		} else if len(run.Applied()) > 0 {
+			updated = append(updated, *run)
+		}
+	}
+	return saveJournal(updated)
+}
+
+func appendJournal(run *adapters.JournalRun) error {
+	path, err := adapters.GetStateFilePath(journalFile)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+func loadJournal() ([]adapters.JournalRun, error) {
+	path, err := adapters.GetStateFilePath(journalFile)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var runs []adapters.JournalRun
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var run adapters.JournalRun
+		if err := json.Unmarshal(scanner.Bytes(), &run); err != nil {
+			return nil, fmt.Errorf("corrupt journal entry in %s, line %d: %w", path, line, err)
+		}
+		runs = append(runs, run)
+	}
+	return runs, scanner.Err()
+}
+
+func saveJournal(runs []adapters.JournalRun) error {
+	path, err := adapters.GetStateFilePath(journalFile)
+	if err != nil {
+		return err
+	}
+	var content strings.Builder
+	for _, run := range runs {
+		data, err := json.Marshal(run)
+		if err != nil {
+			return err
+		}
+		content.Write(append(data, '\n'))
+	}
+	return os.WriteFile(path, []byte(content.String()), 0o600)
+}
+
+// Undo reverses the last run of applied actions that was not undone yet.
+func Undo(taskManager adapters.TaskManagerAdapter, assumeYes bool) error {
+	undoer, ok := taskManager.(adapters.Undoer)
+	if !ok {
+		return adapters.Errorf(adapters.ErrorValidation, "this task manager does not support undo")
+	}
+
+	runs, err := loadJournal()
+	if err != nil {
+		return err
+	}
+	last := -1
+	for i := range runs {
+		if !runs[i].Undone {
+			last = i
+		}
+	}
+	if last == -1 {
+		fmt.Println("Nothing to undo")
+		return nil
+	}
+	run := &runs[last]
+
+	applied := *run
+	applied.Entries = run.Applied()
+	if run.Pending {
+		fmt.Println("gitd was interrupted while applying these actions, some of them may not have been applied.")
+	}
+	fmt.Printf("Undoing the %d actions applied on %s:\n", len(applied.Entries), run.AppliedAt.Format("2006-01-02 15:04"))
+	for _, entry := range applied.Entries {
+		fmt.Printf("  %s %s (%s)\n", undoDescriptions[entry.Action], entry.Task.Content, entry.Task.Project)
+	}
+	if !assumeYes {
+		fmt.Printf("Are you sure you want to continue? (y/n): ")
+		var response string
+		fmt.Scanln(&response)
+		if strings.ToLower(response) != "y" {
+			return adapters.ErrAborted
+		}
+	}
+
+	if err := undoer.UndoActions(&applied); err != nil {
+		var partial *adapters.PartialError
+		if !errors.As(err, &partial) {
+			return err
+		}
+		// Drop what was undone so that retrying does not undo it twice.
+		run.Entries = slices.DeleteFunc(run.Entries, func(entry adapters.JournalEntry) bool {
+			return !entry.Failed && !slices.Contains(partial.Failed, entry.Task.ID)
+		})
+		run.Undone = len(run.Applied()) == 0
+		if saveErr := saveJournal(runs); saveErr != nil {
+			return errors.Join(err, saveErr)
+		}
+		return err
+	}
+	run.Undone = true
+	return saveJournal(runs)
+}
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"errors"
+	"github.com/dormunis/gitd/adapters"
+	"slices"
+	"testing"
+)
+
+func TestApplyJournaled(t *testing.T) {
+	tasks := []adapters.Task{{ID: "1", Content: "one"}, {ID: "2", Content: "two"}}
+	actions := []adapters.TaskAction{
+		{Task: &tasks[0], Action: adapters.ActionDelete},
+		{Task: &tasks[1], Action: adapters.ActionComplete},
+	}
+	failure := errors.New("failed")
+	tests := []struct {
+		name       string
+		err        error
+		wantRuns   int
+		wantFailed []bool
+	}{
+		{"applied", nil, 1, []bool{false, false}},
+		{"partly applied", &adapters.PartialError{Err: failure, Failed: []string{"2"}}, 1, []bool{false, true}},
+		{"nothing applied", &adapters.PartialError{Err: failure, Failed: []string{"1", "2"}}, 0, nil},
+		{"failed before sending", failure, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			err := applyJournaled(&actions, func() error {
+				runs, err := loadJournal()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(runs) != 1 || !runs[0].Pending {
+					t.Errorf("got journal %+v before applying, want one pending run", runs)
+				}
+				return tt.err
+			})
+			if !errors.Is(err, tt.err) {
+				t.Errorf("got error %v, want %v", err, tt.err)
+			}
+
+			runs, err := loadJournal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(runs) != tt.wantRuns {
+				t.Fatalf("got %d runs, want %d", len(runs), tt.wantRuns)
+			}
+			if tt.wantRuns == 0 {
+				return
+			}
+			if runs[0].Pending {
+				t.Error("run is still pending")
+			}
+			for i, entry := range runs[0].Entries {
+				if entry.Failed != tt.wantFailed[i] {
+					t.Errorf("entry %s: got failed %v, want %v", entry.Task.ID, entry.Failed, tt.wantFailed[i])
+				}
+			}
+		})
+	}
+}
+
+type editRecorder struct {
+	adapters.TaskManagerAdapter
+	edits []adapters.TaskEdit
+}
+
+func (r *editRecorder) EditTasks(edits *[]adapters.TaskEdit) error {
+	r.edits = append(r.edits, *edits...)
+	return nil
+}
+
+func TestEditJournaledJournalsDestructiveEdits(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tasks := []adapters.Task{{ID: "1"}, {ID: "2"}, {ID: "3"}}
+	note := "note"
+	edits := []adapters.TaskEdit{
+		{Task: &tasks[0], Delete: true},
+		{Task: &tasks[1], Note: &note},
+		{Task: &tasks[2], Complete: true, Note: &note},
+	}
+	recorder := &editRecorder{}
+	if err := editJournaled(recorder, &edits); err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.edits) != len(edits) {
+		t.Errorf("got %d edits applied, want %d", len(recorder.edits), len(edits))
+	}
+
+	runs, err := loadJournal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || len(runs[0].Entries) != 2 {
+		t.Fatalf("got journal %+v, want one run of two entries", runs)
+	}
+	for i, want := range []adapters.JournalEntry{
+		{Action: adapters.ActionDelete, Task: tasks[0]},
+		{Action: adapters.ActionComplete, Task: tasks[2]},
+	} {
+		if entry := runs[0].Entries[i]; entry.Action != want.Action || entry.Task.ID != want.Task.ID {
+			t.Errorf("entry %d: got %v of %s, want %v of %s", i, entry.Action, entry.Task.ID, want.Action, want.Task.ID)
+		}
+	}
+}
+
+// flakyUndoer fails to undo the given tasks once.
+type flakyUndoer struct {
+	adapters.TaskManagerAdapter
+	failing []string
+	undone  [][]string
+}
+
+func (u *flakyUndoer) UndoActions(run *adapters.JournalRun) error {
+	ids := taskIDs(run)
+	u.undone = append(u.undone, ids)
+	if u.failing == nil {
+		return nil
+	}
+	failed := u.failing
+	u.failing = nil
+	return &adapters.PartialError{Err: errors.New("failed"), Failed: failed}
+}
+
+func TestUndoRetriesWhatWasNotUndone(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tasks := []adapters.Task{{ID: "1"}, {ID: "2"}, {ID: "3"}}
+	actions := []adapters.TaskAction{
+		{Task: &tasks[0], Action: adapters.ActionDelete},
+		{Task: &tasks[1], Action: adapters.ActionDelete},
+		{Task: &tasks[2], Action: adapters.ActionComplete},
+	}
+	if err := applyJournaled(&actions, func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	undoer := &flakyUndoer{failing: []string{"2"}}
+	var partial *adapters.PartialError
+	if err := Undo(undoer, true); !errors.As(err, &partial) {
+		t.Fatalf("got error %v, want the partial failure", err)
+	}
+	runs, err := loadJournal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].Undone || len(runs[0].Entries) != 1 || runs[0].Entries[0].Task.ID != "2" {
+		t.Fatalf("got journal %+v, want only task 2 left to undo", runs)
+	}
+
+	if err := Undo(undoer, true); err != nil {
+		t.Fatal(err)
+	}
+	if len(undoer.undone) != 2 || !slices.Equal(undoer.undone[1], []string{"2"}) {
+		t.Errorf("got undone %v, want task 2 retried alone", undoer.undone)
+	}
+	if runs, err = loadJournal(); err != nil || !runs[0].Undone {
+		t.Errorf("got journal %+v (%v), want the run undone", runs, err)
+	}
+}
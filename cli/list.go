@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"github.com/dormunis/gitd/taskmanagers/taskmanager"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+func List(taskManager adapters.TaskManagerAdapter, filterRequest adapters.FilterRequest, view adapters.ViewConfig, format outputFormat) error {
+	tasks, err := taskManager.FetchTasks()
+	if err != nil {
+		return err
+	}
+
+	filteredTasks, err := taskmanager.FilterTasks(&tasks, &filterRequest)
+	if err != nil {
+		return err
+	}
+	if err := taskmanager.SortTasks(&filteredTasks, view.Sort); err != nil {
+		return adapters.NewError(adapters.ErrorConfig, err)
+	}
+	columns, err := resolveColumns(view.Columns)
+	if err != nil {
+		return adapters.NewError(adapters.ErrorConfig, err)
+	}
+
+	if filteredTasks == nil {
+		filteredTasks = []adapters.Task{}
+	}
+	return writeOutput(format, filteredTasks, func() { printTasks(&filteredTasks, columns) })
+}
+
+func printTasks(tasks *[]adapters.Task, columns []taskColumn) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	titles := make([]string, len(columns))
+	for i, column := range columns {
+		titles[i] = column.Title
+	}
+	fmt.Fprintln(w, strings.Join(titles, "\t"))
+
+	for i := range *tasks {
+		values := make([]string, len(columns))
+		for j, column := range columns {
+			values[j] = column.Value(&(*tasks)[i])
+		}
+		fmt.Fprintln(w, strings.Join(values, "\t"))
+	}
+	w.Flush()
+}
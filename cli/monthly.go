@@ -0,0 +1,285 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"github.com/dormunis/gitd/taskmanagers/taskmanager"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type somedayDecision int8
+
+const (
+	decisionLeave somedayDecision = iota
+	decisionPromote
+	decisionDelete
+)
+
+type monthlyModel struct {
+	tasks     *[]adapters.Task
+	decisions *[]somedayDecision
+	stats     []string
+	cursor    int
+	keys      monthlyKeymap
+	help      help.Model
+	aborted   bool
+}
+
+type monthlyKeymap struct {
+	Up      key.Binding
+	Down    key.Binding
+	Promote key.Binding
+	Delete  key.Binding
+	Leave   key.Binding
+	Save    key.Binding
+	Quit    key.Binding
+	Help    key.Binding
+}
+
+func (k monthlyKeymap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Help, k.Save, k.Quit}
+}
+
+func (k monthlyKeymap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down},
+		{k.Promote, k.Delete, k.Leave},
+		{k.Help, k.Save, k.Quit},
+	}
+}
+
+var monthlyKeys = monthlyKeymap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "move up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "move down"),
+	),
+	Promote: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "promote to active"),
+	),
+	Delete: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "delete"),
+	),
+	Leave: key.NewBinding(
+		key.WithKeys("l", "backspace", "delete"),
+		key.WithHelp("l/backspace", "leave for next month"),
+	),
+	Save: key.NewBinding(
+		key.WithKeys("esc", "q"),
+		key.WithHelp("esc/q", "save and quit"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys("ctrl+c"),
+		key.WithHelp("ctrl+c", "quit without saving"),
+	),
+	Help: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "toggle help"),
+	),
+}
+
+// MonthlyReview shows what was completed during month and walks through the
+// someday/maybe items, grouped by project, to promote, delete or keep them.
+func MonthlyReview(taskManager adapters.TaskManagerAdapter, month time.Time) error {
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	end := start.AddDate(0, 1, 0)
+	completed, err := taskManager.FetchCompletedTasks(start, end)
+	if err != nil {
+		return err
+	}
+	previous, err := taskManager.FetchCompletedTasks(start.AddDate(0, -1, 0), start)
+	if err != nil {
+		return err
+	}
+	stats := completionStats(start, &completed, len(previous))
+	for _, line := range stats {
+		fmt.Println(line)
+	}
+
+	tasks, err := taskManager.FetchTasks()
+	if err != nil {
+		return err
+	}
+	someday, err := taskmanager.FilterTasks(&tasks, &adapters.FilterRequest{Statuses: &somedayStatuses})
+	if err != nil {
+		return err
+	}
+	if len(someday) == 0 {
+		fmt.Println("No someday/maybe items to review")
+		return nil
+	}
+	if err := taskmanager.SortTasks(&someday, []string{"project", "content"}); err != nil {
+		return err
+	}
+
+	decisions := make([]somedayDecision, len(someday))
+	programModel := monthlyModel{
+		tasks:     &someday,
+		decisions: &decisions,
+		stats:     stats,
+		keys:      monthlyKeys,
+		help:      help.New(),
+	}
+	p := tea.NewProgram(programModel, tea.WithAltScreen())
+	finalModel, err := p.Run()
+	if err != nil {
+		return fmt.Errorf("could not run program: %w", err)
+	}
+	if finalModel.(monthlyModel).aborted {
+		return adapters.ErrAborted
+	}
+
+	label := "Monthly review " + time.Now().Format("2006-01-02")
+	var edits []adapters.TaskEdit
+	var promoted, deleted int
+	for i, decision := range decisions {
+		edits = append(edits, somedayEdit(&someday[i], decision, label))
+		switch decision {
+		case decisionPromote:
+			promoted++
+		case decisionDelete:
+			deleted++
+		}
+	}
+
+	fmt.Println("You are about to perform the following actions:")
+	fmt.Printf("Promote %d items to active\n", promoted)
+	fmt.Printf("Delete %d items\n", deleted)
+	fmt.Printf("Leave %d items for next month\n", len(edits)-promoted-deleted)
+	fmt.Printf("Are you sure you want to continue? (y/n): ")
+	var response string
+	fmt.Scanln(&response)
+	if strings.ToLower(response) != "y" {
+		return adapters.ErrAborted
+	}
+	return editJournaled(taskManager, &edits)
+}
+
+// somedayEdit records the decision as a note on the task, so the next review
+// can tell how long an item has been left in someday/maybe.
+func somedayEdit(task *adapters.Task, decision somedayDecision, label string) adapters.TaskEdit {
+	switch decision {
+	case decisionPromote:
+		status := adapters.StatusActive
+		note := label + ": promoted to active"
+		return adapters.TaskEdit{Task: task, Status: &status, Note: &note}
+	case decisionDelete:
+		return adapters.TaskEdit{Task: task, Delete: true}
+	default:
+		note := label + ": left in someday/maybe"
+		return adapters.TaskEdit{Task: task, Note: &note}
+	}
+}
+
+func completionStats(month time.Time, completed *[]adapters.Task, previousCount int) []string {
+	byProject := make(map[string]int)
+	for _, task := range *completed {
+		byProject[task.Project]++
+	}
+	projects := make([]string, 0, len(byProject))
+	for project := range byProject {
+		projects = append(projects, project)
+	}
+	slices.SortFunc(projects, func(a, b string) int {
+		if byProject[a] != byProject[b] {
+			return byProject[b] - byProject[a]
+		}
+		return strings.Compare(a, b)
+	})
+
+	stats := []string{fmt.Sprintf("Completed in %s: %d (previous month: %d)",
+		month.Format("January 2006"), len(*completed), previousCount)}
+	for _, project := range projects {
+		stats = append(stats, fmt.Sprintf("  %-30s %d", project, byProject[project]))
+	}
+	return stats
+}
+
+func (m monthlyModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m monthlyModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, m.keys.Up):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case key.Matches(keyMsg, m.keys.Down):
+		m.advance()
+	case key.Matches(keyMsg, m.keys.Promote):
+		(*m.decisions)[m.cursor] = decisionPromote
+		m.advance()
+	case key.Matches(keyMsg, m.keys.Delete):
+		(*m.decisions)[m.cursor] = decisionDelete
+		m.advance()
+	case key.Matches(keyMsg, m.keys.Leave):
+		(*m.decisions)[m.cursor] = decisionLeave
+	case key.Matches(keyMsg, m.keys.Save):
+		return m, tea.Quit
+	case key.Matches(keyMsg, m.keys.Quit):
+		m.aborted = true
+		return m, tea.Quit
+	case key.Matches(keyMsg, m.keys.Help):
+		m.help.ShowAll = !m.help.ShowAll
+	}
+	return m, nil
+}
+
+func (m *monthlyModel) advance() {
+	if m.cursor < len(*m.tasks)-1 {
+		m.cursor++
+	}
+}
+
+func (m monthlyModel) View() string {
+	columns := []table.Column{
+		{Title: "Action", Width: 10},
+		{Title: "Project", Width: 25},
+		{Title: "Task", Width: 60},
+		{Title: "Updated", Width: 12},
+	}
+
+	var rows []table.Row
+	for i, task := range *m.tasks {
+		mark := "leave"
+		switch (*m.decisions)[i] {
+		case decisionPromote:
+			mark = "promote"
+		case decisionDelete:
+			mark = "delete"
+		}
+		project := task.Project
+		if i > 0 && (*m.tasks)[i-1].Project == task.Project {
+			project = ""
+		}
+		rows = append(rows, table.Row{
+			mark,
+			project,
+			task.Content,
+			task.UpdatedDate.Format("2006-01-02"),
+		})
+	}
+
+	t := newTable(columns, rows, m.cursor)
+	return titleStyle.Render("Monthly review: someday/maybe") + "\n" +
+		strings.Join(m.stats, "\n") + "\n\n" +
+		m.help.View(m.keys) + "\n\n" + t.View()
+}
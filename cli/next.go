@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"github.com/dormunis/gitd/taskmanagers/taskmanager"
+	"slices"
+)
+
+var nextActionsSort = []string{"priority", "due", "updated"}
+
+var nextActionsColumns = []string{"task", "project", "priority", "due", "updated"}
+
+// Next lists next actions, most important first: by priority, then due date,
+// then the ones that have gone untouched the longest. Read-later items are left
+// to gitd read.
+func Next(taskManager adapters.TaskManagerAdapter, filterRequest adapters.FilterRequest, one bool, format outputFormat) error {
+	nextActions, err := fetchNextActions(taskManager, filterRequest)
+	if err != nil {
+		return err
+	}
+
+	if one && len(nextActions) > 1 {
+		nextActions = nextActions[:1]
+	}
+
+	if format != outputTable {
+		if nextActions == nil {
+			nextActions = []adapters.Task{}
+		}
+		return writeOutput(format, nextActions, nil)
+	}
+
+	if len(nextActions) == 0 {
+		fmt.Println("No next actions found")
+		return nil
+	}
+	if one {
+		task := nextActions[0]
+		fmt.Printf("%s (%s)\n", task.Content, task.Project)
+		return nil
+	}
+
+	columns, err := resolveColumns(nextActionsColumns)
+	if err != nil {
+		return err
+	}
+	printTasks(&nextActions, columns)
+	return nil
+}
+
+// fetchNextActions returns the next actions matching filterRequest. Statuses
+// other than next are rejected rather than silently replaced.
+func fetchNextActions(taskManager adapters.TaskManagerAdapter, filterRequest adapters.FilterRequest) ([]adapters.Task, error) {
+	if filterRequest.Statuses != nil && len(*filterRequest.Statuses) > 0 && !slices.Contains(*filterRequest.Statuses, adapters.StatusNext) {
+		return nil, adapters.Errorf(adapters.ErrorValidation, "next only lists next actions, use gitd list for tasks of other statuses")
+	}
+
+	tasks, err := taskManager.FetchTasks()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []adapters.Status{adapters.StatusNext}
+	filterRequest.Statuses = &statuses
+	nextActions, err := taskmanager.FilterTasks(&tasks, &filterRequest)
+	if err != nil {
+		return nil, err
+	}
+	nextActions = withoutArticles(nextActions)
+	if err := taskmanager.SortTasks(&nextActions, nextActionsSort); err != nil {
+		return nil, err
+	}
+	return nextActions, nil
+}
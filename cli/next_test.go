@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"github.com/dormunis/gitd/adapters"
+	"testing"
+	"time"
+)
+
+func TestFetchNextActionsStatuses(t *testing.T) {
+	taskManager := newMemoryTaskManager("memory", time.Now())
+	taskManager.add("Call the plumber", "Home").Status = adapters.StatusNext
+	taskManager.add("Wait for the quote", "Home").Status = adapters.StatusWaiting
+
+	tests := []struct {
+		name     string
+		statuses []adapters.Status
+		want     int
+		invalid  bool
+	}{
+		{"no status", nil, 1, false},
+		{"next", []adapters.Status{adapters.StatusNext}, 1, false},
+		{"next among others", []adapters.Status{adapters.StatusWaiting, adapters.StatusNext}, 1, false},
+		{"other statuses", []adapters.Status{adapters.StatusWaiting}, 0, true},
+	}
+	for _, tt := range tests {
+		filterRequest := adapters.FilterRequest{}
+		if tt.statuses != nil {
+			filterRequest.Statuses = &tt.statuses
+		}
+		tasks, err := fetchNextActions(taskManager, filterRequest)
+		if tt.invalid {
+			if adapters.KindOf(err) != adapters.ErrorValidation {
+				t.Errorf("%s: got error %v, want a validation error", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(tasks) != tt.want || tasks[0].Content != "Call the plumber" {
+			t.Errorf("%s: got %+v, want the next action", tt.name, tasks)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+type outputFormat string
+
+const (
+	outputTable outputFormat = "table"
+	outputJSON  outputFormat = "json"
+	outputYAML  outputFormat = "yaml"
+)
+
+func outputFlag(cmd *cobra.Command) (outputFormat, error) {
+	value, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return "", err
+	}
+	switch format := outputFormat(value); format {
+	case outputTable, outputJSON, outputYAML:
+		return format, nil
+	default:
+		return "", adapters.Errorf(adapters.ErrorValidation, "invalid --output %s, expected table, json or yaml", value)
+	}
+}
+
+// writeOutput prints value as JSON or YAML, or calls printTable for the table
+// format. YAML uses the same field names as JSON.
+func writeOutput(format outputFormat, value any, printTable func()) error {
+	switch format {
+	case outputJSON:
+		return writeJSON(os.Stdout, value)
+	case outputYAML:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		var generic any
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return err
+		}
+		data, err = yaml.Marshal(generic)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	default:
+		printTable()
+		return nil
+	}
+}
+
+func writeJSON(w io.Writer, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// reportError prints err to stderr, as JSON when that is the requested output
+// so that scripts can read it.
+func reportError(err error, format outputFormat) {
+	if format == outputJSON {
+		writeJSON(os.Stderr, map[string]any{
+			"error":     err.Error(),
+			"kind":      adapters.KindOf(err).String(),
+			"exit_code": adapters.ExitCode(err),
+		})
+		return
+	}
+	fmt.Fprintln(os.Stderr, "Error:", err)
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"encoding/json"
+	"github.com/dormunis/gitd/adapters"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// captureStdout returns what run printed to stdout.
+func captureStdout(t *testing.T, run func() error) []byte {
+	path := filepath.Join(t.TempDir(), "stdout")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = file
+	err = run()
+	os.Stdout = stdout
+	file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestInteractiveCommandsListWithOutput(t *testing.T) {
+	now := time.Now()
+	taskManager := newMemoryTaskManager("memory", now)
+	taskManager.add("Call the plumber", "Home").Status = adapters.StatusWaiting
+	taskManager.add("https://example.com/article", "")
+	taskManager.add("Empty project", "Garden")
+
+	tests := []struct {
+		name string
+		run  func(format outputFormat) error
+		want []string
+	}{
+		{"waiting", func(format outputFormat) error { return Waiting(taskManager, format) }, []string{"Call the plumber"}},
+		{"read", func(format outputFormat) error { return Read(taskManager, format) }, []string{"https://example.com/article"}},
+		{"review projects", func(format outputFormat) error {
+			return ReviewProjects(taskManager, adapters.TimeSpan{}, format)
+		}, []string{"Garden", "Home"}},
+	}
+	for _, tt := range tests {
+		var items []map[string]any
+		if err := json.Unmarshal(captureStdout(t, func() error { return tt.run(outputJSON) }), &items); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var got []string
+		for _, item := range items {
+			switch {
+			case item["content"] != nil:
+				got = append(got, item["content"].(string))
+			case item["url"] != nil:
+				got = append(got, item["url"].(string))
+			default:
+				got = append(got, item["project"].(map[string]any)["name"].(string))
+			}
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for _, want := range tt.want {
+			if !slices.Contains(got, want) {
+				t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			}
+		}
+	}
+}
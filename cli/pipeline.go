@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"github.com/dormunis/gitd/query"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ReviewPipeline runs a review process described under reviews in the config
+// file, step by step.
+func ReviewPipeline(taskManager adapters.TaskManagerAdapter, name string, restart bool) error {
+	review, ok := settings.Reviews[name]
+	if !ok {
+		return adapters.Errorf(adapters.ErrorConfig, "unknown review: %s", name)
+	}
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return adapters.Errorf(adapters.ErrorConfig, "invalid review name %q", name)
+	}
+	now, err := userNow(taskManager)
+	if err != nil {
+		return err
+	}
+	steps, err := buildReviewSteps(&review, now.Location())
+	if err != nil {
+		return adapters.Errorf(adapters.ErrorConfig, "invalid review %s: %w", name, err)
+	}
+
+	progressFile := filepath.Join("reviews", name+".json")
+	return runReviewSteps(taskManager, name, progressFile, steps, restart)
+}
+
+func buildReviewSteps(review *adapters.ReviewConfig, location *time.Location) ([]reviewStep, error) {
+	if len(review.Steps) == 0 {
+		return nil, fmt.Errorf("no steps defined")
+	}
+
+	steps := make([]reviewStep, 0, len(review.Steps))
+	for i, stepConfig := range review.Steps {
+		step := reviewStep{
+			Name:     stepConfig.Name,
+			Title:    stepConfig.Prompt,
+			Archiver: stepConfig.Archiver,
+		}
+		if step.Name == "" {
+			step.Name = fmt.Sprintf("step-%d", i+1)
+		}
+		if step.Title == "" {
+			step.Title = step.Name
+		}
+		if slices.ContainsFunc(steps, func(other reviewStep) bool { return other.Name == step.Name }) {
+			return nil, fmt.Errorf("duplicate step name %s", step.Name)
+		}
+
+		filterRequest := adapters.FilterRequest{Location: location}
+		if stepConfig.Filter != "" {
+			expr, err := query.Parse(stepConfig.Filter)
+			if err != nil {
+				return nil, fmt.Errorf("step %s: %w", step.Name, err)
+			}
+			filterRequest.Query = expr
+		}
+		step.Select = selectByFilter(filterRequest)
+
+		for _, actionName := range stepConfig.Actions {
+			action, err := adapters.ParseAction(actionName)
+			if err != nil {
+				return nil, fmt.Errorf("step %s: %w", step.Name, err)
+			}
+			step.Actions = append(step.Actions, action)
+		}
+
+		if step.Archiver != "" {
+			if _, ok := settings.Archivers[step.Archiver]; !ok {
+				return nil, fmt.Errorf("step %s: unknown archiver %s", step.Name, step.Archiver)
+			}
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"github.com/dormunis/gitd/adapters"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildReviewSteps(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []adapters.ReviewStepConfig
+		want  []string
+		err   string
+	}{
+		{"named and unnamed steps", []adapters.ReviewStepConfig{{Name: "someday"}, {Filter: "tag:waiting"}}, []string{"someday", "step-2"}, ""},
+		{"duplicate names", []adapters.ReviewStepConfig{{Name: "someday"}, {Name: "someday"}}, nil, "duplicate step name someday"},
+		{"name of an unnamed step", []adapters.ReviewStepConfig{{Name: "step-2"}, {}}, nil, "duplicate step name step-2"},
+		{"no steps", nil, nil, "no steps defined"},
+		{"invalid filter", []adapters.ReviewStepConfig{{Name: "broken", Filter: "tag:"}}, nil, "step broken"},
+	}
+	for _, tt := range tests {
+		steps, err := buildReviewSteps(&adapters.ReviewConfig{Steps: tt.steps}, time.UTC)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: got error %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var names []string
+		for _, step := range steps {
+			names = append(names, step.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: got steps %v, want %v", tt.name, names, tt.want)
+		}
+	}
+}
+
+func TestReviewPipelineNames(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.Reviews = map[string]adapters.ReviewConfig{"../weekly": {Steps: []adapters.ReviewStepConfig{{Name: "one"}}}}
+
+	taskManager := newMemoryTaskManager("memory", time.Now())
+	for _, name := range []string{"../weekly", "missing"} {
+		if err := ReviewPipeline(taskManager, name, false); adapters.KindOf(err) != adapters.ErrorConfig {
+			t.Errorf("%s: got error %v, want a config error", name, err)
+		}
+	}
+}
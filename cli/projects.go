@@ -0,0 +1,297 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"github.com/dormunis/gitd/taskmanagers/taskmanager"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// nextLabel marks the next actions added to projects, as drafts have no status.
+const nextLabel = "next"
+
+type projectDecision struct {
+	NextAction string
+	Archive    bool
+}
+
+type projectsModel struct {
+	audits    *[]taskmanager.ProjectAudit
+	decisions *[]projectDecision
+	cursor    int
+	adding    bool
+	input     textinput.Model
+	keys      projectsKeymap
+	help      help.Model
+	aborted   bool
+}
+
+type projectsKeymap struct {
+	Up      key.Binding
+	Down    key.Binding
+	AddNext key.Binding
+	Archive key.Binding
+	Reset   key.Binding
+	Save    key.Binding
+	Quit    key.Binding
+	Help    key.Binding
+}
+
+func (k projectsKeymap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Help, k.Save, k.Quit}
+}
+
+func (k projectsKeymap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down},
+		{k.AddNext, k.Archive, k.Reset},
+		{k.Help, k.Save, k.Quit},
+	}
+}
+
+var projectsKeys = projectsKeymap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "move up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "move down"),
+	),
+	AddNext: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "add next action"),
+	),
+	Archive: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "archive project"),
+	),
+	Reset: key.NewBinding(
+		key.WithKeys("backspace", "delete"),
+		key.WithHelp("backspace/delete", "remove selection"),
+	),
+	Save: key.NewBinding(
+		key.WithKeys("esc", "q"),
+		key.WithHelp("esc/q", "save and quit"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys("ctrl+c"),
+		key.WithHelp("ctrl+c", "quit without saving"),
+	),
+	Help: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "toggle help"),
+	),
+}
+
+// ReviewProjects lists active projects without a next action or untouched
+// for longer than untouchedFor, and lets the user add a next action to them
+// or archive them. With JSON or YAML output the audits are only printed.
+func ReviewProjects(taskManager adapters.TaskManagerAdapter, untouchedFor adapters.TimeSpan, format outputFormat) error {
+	projects, err := taskManager.FetchProjects()
+	if err != nil {
+		return err
+	}
+	tasks, err := taskManager.FetchTasks()
+	if err != nil {
+		return err
+	}
+
+	audits := taskmanager.AuditProjects(&projects, &tasks, untouchedFor, time.Now())
+	if format != outputTable {
+		if audits == nil {
+			audits = []taskmanager.ProjectAudit{}
+		}
+		return writeOutput(format, audits, nil)
+	}
+	if len(audits) == 0 {
+		fmt.Println("Every active project has a next action and recent activity")
+		return nil
+	}
+
+	decisions := make([]projectDecision, len(audits))
+	programModel := projectsModel{
+		audits:    &audits,
+		decisions: &decisions,
+		input:     textinput.New(),
+		keys:      projectsKeys,
+		help:      help.New(),
+	}
+	p := tea.NewProgram(programModel, tea.WithAltScreen())
+	finalModel, err := p.Run()
+	if err != nil {
+		return fmt.Errorf("could not run program: %w", err)
+	}
+	if finalModel.(projectsModel).aborted {
+		return adapters.ErrAborted
+	}
+
+	drafts, archived := projectChanges(audits, decisions)
+	if len(drafts) == 0 && len(archived) == 0 {
+		return nil
+	}
+
+	fmt.Println("You are about to perform the following actions:")
+	fmt.Printf("Add %d next actions\n", len(drafts))
+	fmt.Printf("Archive %d projects\n", len(archived))
+	fmt.Printf("Are you sure you want to continue? (y/n): ")
+	var response string
+	fmt.Scanln(&response)
+	if strings.ToLower(response) != "y" {
+		return adapters.ErrAborted
+	}
+
+	if len(drafts) > 0 {
+		if err := taskManager.CreateTasks(&drafts); err != nil {
+			return err
+		}
+	}
+	if len(archived) > 0 {
+		if err := taskManager.ArchiveProjects(&archived); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// projectChanges turns the decisions into a next action per project to add
+// one to, and the projects to archive.
+func projectChanges(audits []taskmanager.ProjectAudit, decisions []projectDecision) ([]adapters.TaskDraft, []adapters.Project) {
+	var drafts []adapters.TaskDraft
+	var archived []adapters.Project
+	for i, decision := range decisions {
+		if decision.Archive {
+			archived = append(archived, audits[i].Project)
+		} else if decision.NextAction != "" {
+			drafts = append(drafts, adapters.TaskDraft{
+				Content:   decision.NextAction,
+				ProjectID: audits[i].Project.ID,
+				Tags:      []string{nextLabel},
+			})
+		}
+	}
+	return drafts, archived
+}
+
+func (m projectsModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m projectsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	decision := &(*m.decisions)[m.cursor]
+	if m.adding {
+		switch keyMsg.Type {
+		case tea.KeyEsc:
+			m.adding = false
+			m.input.Blur()
+			return m, nil
+		case tea.KeyEnter:
+			decision.NextAction = strings.TrimSpace(m.input.Value())
+			decision.Archive = false
+			m.adding = false
+			m.input.Blur()
+			m.advance()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(keyMsg)
+		return m, cmd
+	}
+
+	switch {
+	case key.Matches(keyMsg, m.keys.Up):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case key.Matches(keyMsg, m.keys.Down):
+		m.advance()
+	case key.Matches(keyMsg, m.keys.AddNext):
+		m.adding = true
+		m.input.Reset()
+		m.input.SetValue(decision.NextAction)
+		m.input.Placeholder = "next action for " + (*m.audits)[m.cursor].Project.Name
+		return m, m.input.Focus()
+	case key.Matches(keyMsg, m.keys.Archive):
+		decision.Archive = !decision.Archive
+		if decision.Archive {
+			m.advance()
+		}
+	case key.Matches(keyMsg, m.keys.Reset):
+		*decision = projectDecision{}
+	case key.Matches(keyMsg, m.keys.Save):
+		return m, tea.Quit
+	case key.Matches(keyMsg, m.keys.Quit):
+		m.aborted = true
+		return m, tea.Quit
+	case key.Matches(keyMsg, m.keys.Help):
+		m.help.ShowAll = !m.help.ShowAll
+	}
+	return m, nil
+}
+
+func (m *projectsModel) advance() {
+	if m.cursor < len(*m.audits)-1 {
+		m.cursor++
+	}
+}
+
+func (m projectsModel) View() string {
+	columns := []table.Column{
+		{Title: "Action", Width: 8},
+		{Title: "Project", Width: 30},
+		{Title: "Sections", Width: 8},
+		{Title: "Tasks", Width: 6},
+		{Title: "Last Activity", Width: 14},
+		{Title: "Issues", Width: 30},
+		{Title: "Next Action", Width: 40},
+	}
+
+	var rows []table.Row
+	for i, audit := range *m.audits {
+		decision := (*m.decisions)[i]
+		mark := " "
+		if decision.Archive {
+			mark = "x"
+		} else if decision.NextAction != "" {
+			mark = "+"
+		}
+
+		var issues []string
+		if audit.MissingNextAction {
+			issues = append(issues, "no next action")
+		}
+		if audit.Untouched {
+			issues = append(issues, "untouched")
+		}
+
+		rows = append(rows, table.Row{
+			fmt.Sprintf("[%s]", mark),
+			audit.Project.Name,
+			fmt.Sprintf("%d", len(audit.Project.Sections)),
+			fmt.Sprintf("%d", audit.TaskCount),
+			formatOptionalDate(audit.LastActivity),
+			strings.Join(issues, ", "),
+			decision.NextAction,
+		})
+	}
+
+	t := newTable(columns, rows, m.cursor)
+
+	view := titleStyle.Render("Projects without a next action or recent activity") + "\n" + m.help.View(m.keys) + "\n\n" + t.View()
+	if m.adding {
+		view += "\n\nNext action: " + m.input.View()
+	}
+	return view
+}
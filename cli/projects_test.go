@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"github.com/dormunis/gitd/adapters"
+	"github.com/dormunis/gitd/taskmanagers/taskmanager"
+	"testing"
+)
+
+func TestProjectChanges(t *testing.T) {
+	audits := []taskmanager.ProjectAudit{
+		{Project: adapters.Project{ID: "p1", Name: "Garden"}},
+		{Project: adapters.Project{ID: "p2", Name: "Taxes"}},
+		{Project: adapters.Project{ID: "p3", Name: "Old"}},
+		{Project: adapters.Project{ID: "p4", Name: "Skipped"}},
+	}
+	decisions := []projectDecision{
+		{NextAction: "Buy seeds"},
+		{NextAction: "Collect receipts"},
+		{Archive: true},
+		{},
+	}
+
+	drafts, archived := projectChanges(audits, decisions)
+	want := []adapters.TaskDraft{
+		{Content: "Buy seeds", ProjectID: "p1"},
+		{Content: "Collect receipts", ProjectID: "p2"},
+	}
+	if len(drafts) != len(want) {
+		t.Fatalf("got %d drafts, want %d", len(drafts), len(want))
+	}
+	for i, draft := range drafts {
+		if draft.Content != want[i].Content || draft.ProjectID != want[i].ProjectID {
+			t.Errorf("draft %d: got %q in %s, want %q in %s", i, draft.Content, draft.ProjectID, want[i].Content, want[i].ProjectID)
+		}
+		if len(draft.Tags) != 1 || draft.Tags[0] != nextLabel {
+			t.Errorf("draft %d: got tags %v, want [%s]", i, draft.Tags, nextLabel)
+		}
+	}
+	if len(archived) != 1 || archived[0].ID != "p3" {
+		t.Errorf("got archived %+v, want p3", archived)
+	}
+}
@@ -1,10 +1,13 @@
 package cli
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/dormunis/gitd/adapters"
 	"github.com/dormunis/gitd/taskmanagers/taskmanager"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/help"
@@ -15,12 +18,16 @@ import (
 )
 
 type model struct {
-	taskmanager adapters.TaskManagerAdapter
-	cursor      int
-	actions     *[]adapters.TaskAction
-	tasks       *[]adapters.Task
-	keys        keymap
-	help        help.Model
+	taskmanager   adapters.TaskManagerAdapter
+	cursor        int
+	actions       *[]adapters.TaskAction
+	tasks         *[]adapters.Task
+	columns       []taskColumn
+	title         string
+	defaultAction adapters.Action
+	keys          keymap
+	help          help.Model
+	aborted       bool
 }
 
 type keymap struct {
@@ -91,41 +98,150 @@ var keys = keymap{
 	),
 }
 
-func Purge(taskManager adapters.TaskManagerAdapter, timespan adapters.TimeSpan) {
+// PurgeOptions pick how purge decisions are made: in the TUI by default, in
+// $EDITOR, or without interaction from a single action or a plan file.
+type PurgeOptions struct {
+	Editor   bool
+	Action   *adapters.Action // applied to every filtered task
+	PlanFile string           // JSON object mapping task IDs to action names
+	Yes      bool             // skip the confirmation
+	DryRun   bool             // print the commands instead of sending them
+	Out      string           // save the commands as a plan for gitd apply
+}
+
+func Purge(taskManager adapters.TaskManagerAdapter, filterRequest adapters.FilterRequest, view adapters.ViewConfig, options PurgeOptions, format outputFormat) error {
 	// TODO: make this use a loader
 	tasks, err := taskManager.FetchTasks()
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		return err
 	}
 
-	filterRequest := adapters.FilterRequest{
-		AfterTimeSpan: &timespan,
+	if options.PlanFile != "" {
+		actions, err := loadActionPlan(options.PlanFile, &tasks)
+		if err != nil {
+			return err
+		}
+		return finishPurge(taskManager, &actions, options, format)
 	}
+
 	filteredTasks, err := taskmanager.FilterTasks(&tasks, &filterRequest)
-	actions := make([]adapters.TaskAction, len(*&filteredTasks))
+	if err != nil {
+		return err
+	}
+	if err := taskmanager.SortTasks(&filteredTasks, view.Sort); err != nil {
+		return adapters.NewError(adapters.ErrorConfig, err)
+	}
+	columns, err := resolveColumns(view.Columns)
+	if err != nil {
+		return adapters.NewError(adapters.ErrorConfig, err)
+	}
+
+	if len(filteredTasks) == 0 && (options.Editor || options.Action != nil) {
+		fmt.Println("No tasks to purge")
+		return nil
+	}
+
+	var actions []adapters.TaskAction
+	switch {
+	case options.Action != nil:
+		for i := range filteredTasks {
+			actions = append(actions, adapters.TaskAction{Task: &filteredTasks[i], Action: *options.Action})
+		}
+	case options.Editor:
+		actions, err = editTasks(&filteredTasks, "Purge")
+	default:
+		actions, err = reviewTasks(&filteredTasks, columns, "", nil, adapters.ActionRevalidate)
+	}
+	if err != nil {
+		return err
+	}
+	return finishPurge(taskManager, &actions, options, format)
+}
+
+func finishPurge(taskManager adapters.TaskManagerAdapter, actions *[]adapters.TaskAction, options PurgeOptions, format outputFormat) error {
+	if options.DryRun || options.Out != "" {
+		return planActions(taskManager, actions, options.Out, format)
+	}
+	return savePurge(taskManager, actions, options.Yes)
+}
+
+// loadActionPlan reads a plan file such as {"7025591234": "defer"} and
+// resolves its task IDs against tasks.
+func loadActionPlan(path string, tasks *[]adapters.Task) ([]adapters.TaskAction, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var plan map[string]string
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, adapters.Errorf(adapters.ErrorValidation, "invalid plan file %s: %w", path, err)
+	}
+
+	ids := make([]string, 0, len(plan))
+	for id := range plan {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	var actions []adapters.TaskAction
+	for _, id := range ids {
+		action, err := adapters.ParseAction(plan[id])
+		if err != nil {
+			return nil, adapters.Errorf(adapters.ErrorValidation, "task %s in %s: %w", id, path, err)
+		}
+		index := slices.IndexFunc(*tasks, func(task adapters.Task) bool { return task.ID == id })
+		if index == -1 {
+			return nil, adapters.Errorf(adapters.ErrorValidation, "task %s in %s not found", id, path)
+		}
+		actions = append(actions, adapters.TaskAction{Task: &(*tasks)[index], Action: action})
+	}
+	return actions, nil
+}
+
+// reviewTasks runs the purge TUI over tasks and returns the action the user
+// picked for each of them. When allowed is not nil, only those actions can be
+// picked; tasks are left with defaultAction, or ignored if that is not
+// allowed.
+func reviewTasks(tasks *[]adapters.Task, columns []taskColumn, title string, allowed []adapters.Action, defaultAction adapters.Action) ([]adapters.TaskAction, error) {
+	actions := make([]adapters.TaskAction, len(*tasks))
+	if len(*tasks) == 0 {
+		return actions, nil
+	}
 
 	programModel := model{
-		tasks:   &filteredTasks,
-		actions: &actions,
-		keys:    keys,
-		help:    help.New(),
+		tasks:         tasks,
+		actions:       &actions,
+		columns:       columns,
+		title:         title,
+		defaultAction: defaultAction,
+		keys:          keys,
+		help:          help.New(),
+	}
+	if allowed != nil {
+		programModel.keys.Complete.SetEnabled(slices.Contains(allowed, adapters.ActionComplete))
+		programModel.keys.Ignore.SetEnabled(slices.Contains(allowed, adapters.ActionIgnore))
+		programModel.keys.Defer.SetEnabled(slices.Contains(allowed, adapters.ActionDefer))
+		programModel.keys.Delete.SetEnabled(slices.Contains(allowed, adapters.ActionDelete))
+		if !slices.Contains(allowed, defaultAction) {
+			programModel.defaultAction = adapters.ActionIgnore
+		}
 	}
 
 	p := tea.NewProgram(programModel, tea.WithAltScreen())
-	_, err = p.Run()
+	finalModel, err := p.Run()
 	if err != nil {
-		fmt.Println("could not run program:", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("could not run program: %w", err)
 	}
-
-	SavePurge(taskManager, &actions)
+	if finalModel.(model).aborted {
+		return nil, adapters.ErrAborted
+	}
+	return actions, nil
 }
 
 func (m model) Init() tea.Cmd {
 	for i := range *m.tasks {
 		(*m.actions)[i].Task = &(*m.tasks)[i]
-		(*m.actions)[i].Action = adapters.ActionRevalidate
+		(*m.actions)[i].Action = m.defaultAction
 	}
 	return nil
 }
@@ -143,7 +259,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.cursor++
 			}
 		case key.Matches(msg, m.keys.Revalidate):
-			(*m.actions)[m.cursor].Action = adapters.ActionRevalidate
+			(*m.actions)[m.cursor].Action = m.defaultAction
 
 		case key.Matches(msg, m.keys.Ignore):
 			(*m.actions)[m.cursor].Action = adapters.ActionIgnore
@@ -168,7 +284,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, m.keys.Save):
 			return m, tea.Quit
 		case key.Matches(msg, m.keys.Quit):
-			os.Exit(1)
+			m.aborted = true
 			return m, tea.Quit
 		case key.Matches(msg, m.keys.Help):
 			m.help.ShowAll = !m.help.ShowAll
@@ -179,33 +295,32 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m model) createTable() table.Model {
-	columns := []table.Column{
-		{Title: "Action", Width: 8},
-		{Title: "Task", Width: 65},
-		{Title: "Project", Width: 30},
-		{Title: "Creation Date", Width: 18},
-		{Title: "Last Modified Date", Width: 18},
+	columns := []table.Column{{Title: "Action", Width: 8}}
+	for _, column := range m.columns {
+		columns = append(columns, table.Column{Title: column.Title, Width: column.Width})
 	}
 
 	var rows []table.Row
 
 	for _, taskAction := range *m.actions {
 		mark := getActionString(&taskAction)
-		rows = append(rows, table.Row{
-			fmt.Sprintf("[%s]", mark),
-			taskAction.Task.Content,
-			taskAction.Task.Project,
-			taskAction.Task.CreatedDate.Format("2006-01-02"),
-			taskAction.Task.UpdatedDate.Format("2006-01-02"),
-		})
+		row := table.Row{fmt.Sprintf("[%s]", mark)}
+		for _, column := range m.columns {
+			row = append(row, column.Value(taskAction.Task))
+		}
+		rows = append(rows, row)
 	}
 
+	return newTable(columns, rows, m.cursor)
+}
+
+func newTable(columns []table.Column, rows []table.Row, cursor int) table.Model {
 	t := table.New(
 		table.WithColumns(columns),
 		table.WithRows(rows),
 		table.WithFocused(true),
 	)
-	t.SetCursor(m.cursor)
+	t.SetCursor(cursor)
 
 	s := table.DefaultStyles()
 	s.Header = s.Header.
@@ -221,9 +336,14 @@ func (m model) createTable() table.Model {
 func (m model) View() string {
 	helpView := m.help.View(m.keys)
 	t := m.createTable()
+	if m.title != "" {
+		return titleStyle.Render(m.title) + "\n" + helpView + "\n\n" + t.View()
+	}
 	return helpView + "\n\n" + t.View()
 }
 
+var titleStyle = lipgloss.NewStyle().Bold(true).MarginBottom(1)
+
 func getActionString(action *adapters.TaskAction) string {
 	switch action.Action {
 	case adapters.ActionDelete:
@@ -241,14 +361,38 @@ func getActionString(action *adapters.TaskAction) string {
 	}
 }
 
-func SavePurge(taskManager interface{ adapters.TaskManagerAdapter }, actions *[]adapters.TaskAction) {
-	if !verifyPurge(actions) {
-		return
+// SavePurge asks for confirmation and applies the actions, reporting whether
+// they were applied. Declining is not an error, so that a review can go on.
+func SavePurge(taskManager adapters.TaskManagerAdapter, actions *[]adapters.TaskAction) (bool, error) {
+	if !hasPurgeChanges(actions) {
+		return false, nil
+	}
+	err := savePurge(taskManager, actions, false)
+	if errors.Is(err, adapters.ErrAborted) {
+		return false, nil
 	}
-	taskManager.UpdateTasks(actions)
+	return err == nil, err
 }
 
-func verifyPurge(actions *[]adapters.TaskAction) bool {
+// savePurge applies the actions once confirmed, returning adapters.ErrAborted
+// when the user declines.
+func savePurge(taskManager adapters.TaskManagerAdapter, actions *[]adapters.TaskAction, assumeYes bool) error {
+	if !hasPurgeChanges(actions) {
+		return nil
+	}
+	if !verifyPurge(actions, assumeYes) {
+		return adapters.ErrAborted
+	}
+	return applyJournaled(actions, func() error { return taskManager.UpdateTasks(actions) })
+}
+
+func hasPurgeChanges(actions *[]adapters.TaskAction) bool {
+	return slices.ContainsFunc(*actions, func(action adapters.TaskAction) bool {
+		return action.Action != adapters.ActionIgnore
+	})
+}
+
+func verifyPurge(actions *[]adapters.TaskAction, assumeYes bool) bool {
 	deleteCount := 0
 	deferCount := 0
 	revalidateCount := 0
@@ -267,15 +411,14 @@ func verifyPurge(actions *[]adapters.TaskAction) bool {
 		}
 	}
 
-	if deleteCount == 0 && deferCount == 0 && revalidateCount == 0 && completeCount == 0 {
-		return false
-	}
-
 	fmt.Println("You are about to perform the following actions:")
 	fmt.Printf("Delete %d items\n", deleteCount)
 	fmt.Printf("Archiving %d items\n", deferCount)
 	fmt.Printf("Revalidating %d items\n", revalidateCount)
 	fmt.Printf("Completing %d items\n", completeCount)
+	if assumeYes {
+		return true
+	}
 	fmt.Printf("Are you sure you want to continue? (y/n): ")
 	var response string
 	fmt.Scanln(&response)
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"github.com/dormunis/gitd/adapters"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestPurgeModelDefaultAction(t *testing.T) {
+	tests := []struct {
+		name          string
+		defaultAction adapters.Action
+		keys          []string
+		want          []adapters.Action
+	}{
+		{"purge revalidates by default", adapters.ActionRevalidate, nil, []adapters.Action{adapters.ActionRevalidate, adapters.ActionRevalidate}},
+		{"review steps ignore by default", adapters.ActionIgnore, nil, []adapters.Action{adapters.ActionIgnore, adapters.ActionIgnore}},
+		{"reset to the default", adapters.ActionIgnore, []string{"v", "k", "backspace"}, []adapters.Action{adapters.ActionIgnore, adapters.ActionIgnore}},
+	}
+	for _, tt := range tests {
+		tasks := []adapters.Task{{ID: "1"}, {ID: "2"}}
+		actions := make([]adapters.TaskAction, len(tasks))
+		var m tea.Model = model{tasks: &tasks, actions: &actions, defaultAction: tt.defaultAction, keys: keys}
+		m.Init()
+		for _, k := range tt.keys {
+			msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+			if k == "backspace" {
+				msg = tea.KeyMsg{Type: tea.KeyBackspace}
+			}
+			m, _ = m.Update(msg)
+		}
+		for i, want := range tt.want {
+			if actions[i].Action != want {
+				t.Errorf("%s: task %d got %v, want %v", tt.name, i+1, actions[i].Action, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"github.com/dormunis/gitd/archivers/archiver"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const defaultArticleLabel = "read_later"
+
+type articleDecision int8
+
+const (
+	articleKeep articleDecision = iota
+	articleRead
+	articleToReadingList
+)
+
+type readModel struct {
+	articles  *[]adapters.Article
+	decisions *[]articleDecision
+	cursor    int
+	summary   bool
+	input     textinput.Model
+	keys      readKeymap
+	help      help.Model
+	aborted   bool
+}
+
+type readKeymap struct {
+	Up          key.Binding
+	Down        key.Binding
+	Read        key.Binding
+	ReadingList key.Binding
+	Reset       key.Binding
+	Save        key.Binding
+	Quit        key.Binding
+	Help        key.Binding
+}
+
+func (k readKeymap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Help, k.Save, k.Quit}
+}
+
+func (k readKeymap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down},
+		{k.Read, k.ReadingList, k.Reset},
+		{k.Help, k.Save, k.Quit},
+	}
+}
+
+var readKeys = readKeymap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "move up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "move down"),
+	),
+	Read: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "mark read, with an optional summary"),
+	),
+	ReadingList: key.NewBinding(
+		key.WithKeys("l"),
+		key.WithHelp("l", "move to reading list"),
+	),
+	Reset: key.NewBinding(
+		key.WithKeys("backspace", "delete"),
+		key.WithHelp("backspace/delete", "remove selection"),
+	),
+	Save: key.NewBinding(
+		key.WithKeys("esc", "q"),
+		key.WithHelp("esc/q", "save and quit"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys("ctrl+c"),
+		key.WithHelp("ctrl+c", "quit without saving"),
+	),
+	Help: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "toggle help"),
+	),
+}
+
+func articleLabel() string {
+	if settings.Articles.Label != "" {
+		return settings.Articles.Label
+	}
+	return defaultArticleLabel
+}
+
+func findArticles(tasks *[]adapters.Task) []adapters.Article {
+	var articles []adapters.Article
+	for i := range *tasks {
+		if article, ok := adapters.ParseArticle(&(*tasks)[i], articleLabel()); ok {
+			articles = append(articles, *article)
+		}
+	}
+	return articles
+}
+
+func isArticle(task *adapters.Task) bool {
+	_, ok := adapters.ParseArticle(task, articleLabel())
+	return ok
+}
+
+// withoutArticles drops read-later items, which are not actions.
+func withoutArticles(tasks []adapters.Task) []adapters.Task {
+	var actions []adapters.Task
+	for i := range tasks {
+		if !isArticle(&tasks[i]) {
+			actions = append(actions, tasks[i])
+		}
+	}
+	return actions
+}
+
+// Read lists read-later items. Read ones are completed and documented in the
+// articles archiver; the others can be moved out of the task manager into the
+// reading list. With JSON or YAML output the items are only printed.
+func Read(taskManager adapters.TaskManagerAdapter, format outputFormat) error {
+	tasks, err := taskManager.FetchTasks()
+	if err != nil {
+		return err
+	}
+	articles := findArticles(&tasks)
+	if format != outputTable {
+		if articles == nil {
+			articles = []adapters.Article{}
+		}
+		return writeOutput(format, articles, nil)
+	}
+	if len(articles) == 0 {
+		fmt.Println("Nothing to read")
+		return nil
+	}
+
+	decisions := make([]articleDecision, len(articles))
+	programModel := readModel{
+		articles:  &articles,
+		decisions: &decisions,
+		input:     textinput.New(),
+		keys:      readKeys,
+		help:      help.New(),
+	}
+	p := tea.NewProgram(programModel, tea.WithAltScreen())
+	finalModel, err := p.Run()
+	if err != nil {
+		return fmt.Errorf("could not run program: %w", err)
+	}
+	if finalModel.(readModel).aborted {
+		return adapters.ErrAborted
+	}
+
+	var read, readingList []adapters.Article
+	for i, decision := range decisions {
+		switch decision {
+		case articleRead:
+			articles[i].Read = true
+			read = append(read, articles[i])
+		case articleToReadingList:
+			readingList = append(readingList, articles[i])
+		}
+	}
+	if len(read) == 0 && len(readingList) == 0 {
+		return nil
+	}
+	if len(read) > 0 && settings.Articles.Archiver == "" {
+		return adapters.Errorf(adapters.ErrorConfig, "articles archiver is not configured")
+	}
+	if len(readingList) > 0 && settings.Articles.ReadingList == "" {
+		return adapters.Errorf(adapters.ErrorConfig, "articles reading list is not configured")
+	}
+
+	fmt.Println("You are about to perform the following actions:")
+	fmt.Printf("Mark %d articles as read\n", len(read))
+	fmt.Printf("Move %d articles to the reading list\n", len(readingList))
+	fmt.Printf("Are you sure you want to continue? (y/n): ")
+	var response string
+	fmt.Scanln(&response)
+	if strings.ToLower(response) != "y" {
+		return adapters.ErrAborted
+	}
+
+	// Document the articles before touching the tasks, so that a failure
+	// never loses a link.
+	if err := archiveArticles(settings.Articles.Archiver, "Read", &read); err != nil {
+		return err
+	}
+	if err := archiveArticles(settings.Articles.ReadingList, "Reading list", &readingList); err != nil {
+		return err
+	}
+
+	var edits []adapters.TaskEdit
+	for _, article := range read {
+		edit := adapters.TaskEdit{Task: article.Task, Complete: true}
+		if article.Summary != "" {
+			summary := article.Summary
+			edit.Note = &summary
+		}
+		edits = append(edits, edit)
+	}
+	for _, article := range readingList {
+		edits = append(edits, adapters.TaskEdit{Task: article.Task, Delete: true})
+	}
+	return editJournaled(taskManager, &edits)
+}
+
+func archiveArticles(name string, title string, articles *[]adapters.Article) error {
+	if len(*articles) == 0 {
+		return nil
+	}
+	archiverAdapter, err := archiver.Initialize(name, settings)
+	if err != nil {
+		return err
+	}
+	return archiverAdapter.ArchiveArticles(title, articles)
+}
+
+func (m readModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m readModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	if m.summary {
+		switch keyMsg.Type {
+		case tea.KeyEsc:
+			m.summary = false
+			m.input.Blur()
+			return m, nil
+		case tea.KeyEnter:
+			(*m.articles)[m.cursor].Summary = strings.TrimSpace(m.input.Value())
+			(*m.decisions)[m.cursor] = articleRead
+			m.summary = false
+			m.input.Blur()
+			m.advance()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(keyMsg)
+		return m, cmd
+	}
+
+	switch {
+	case key.Matches(keyMsg, m.keys.Up):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case key.Matches(keyMsg, m.keys.Down):
+		m.advance()
+	case key.Matches(keyMsg, m.keys.Read):
+		m.summary = true
+		m.input.Reset()
+		m.input.SetValue((*m.articles)[m.cursor].Summary)
+		m.input.Placeholder = "summary (optional)"
+		return m, m.input.Focus()
+	case key.Matches(keyMsg, m.keys.ReadingList):
+		(*m.decisions)[m.cursor] = articleToReadingList
+		m.advance()
+	case key.Matches(keyMsg, m.keys.Reset):
+		(*m.decisions)[m.cursor] = articleKeep
+		(*m.articles)[m.cursor].Summary = ""
+	case key.Matches(keyMsg, m.keys.Save):
+		return m, tea.Quit
+	case key.Matches(keyMsg, m.keys.Quit):
+		m.aborted = true
+		return m, tea.Quit
+	case key.Matches(keyMsg, m.keys.Help):
+		m.help.ShowAll = !m.help.ShowAll
+	}
+	return m, nil
+}
+
+func (m *readModel) advance() {
+	if m.cursor < len(*m.articles)-1 {
+		m.cursor++
+	}
+}
+
+func (m readModel) View() string {
+	columns := []table.Column{
+		{Title: "Action", Width: 8},
+		{Title: "Title", Width: 45},
+		{Title: "URL", Width: 45},
+		{Title: "Added", Width: 12},
+		{Title: "Summary", Width: 30},
+	}
+
+	var rows []table.Row
+	for i, article := range *m.articles {
+		mark := " "
+		switch (*m.decisions)[i] {
+		case articleRead:
+			mark = "r"
+		case articleToReadingList:
+			mark = "l"
+		}
+		rows = append(rows, table.Row{
+			fmt.Sprintf("[%s]", mark),
+			article.Title,
+			article.URL,
+			article.Task.CreatedDate.Format("2006-01-02"),
+			article.Summary,
+		})
+	}
+
+	t := newTable(columns, rows, m.cursor)
+
+	view := titleStyle.Render("Read later") + "\n" + m.help.View(m.keys) + "\n\n" + t.View()
+	if m.summary {
+		view += "\n\nSummary: " + m.input.View()
+	}
+	return view
+}
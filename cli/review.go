@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"github.com/dormunis/gitd/archivers/archiver"
+	"github.com/dormunis/gitd/taskmanagers/taskmanager"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+type reviewStep struct {
+	Name     string
+	Title    string
+	Run      func(taskManager adapters.TaskManagerAdapter) error // runs a dedicated review instead of Select
+	Select   func(tasks *[]adapters.Task) ([]adapters.Task, error)
+	Actions  []adapters.Action // nil allows every action
+	Archiver string            // optional, documents the applied actions
+}
+
+// reviewProgress records which steps of a review are done, so that an
+// interrupted review resumes where it stopped.
+type reviewProgress struct {
+	StartedAt      time.Time `json:"started_at"`
+	CompletedSteps []string  `json:"completed_steps"`
+}
+
+var reviewColumns = []string{"task", "project", "tags", "updated"}
+
+// runReviewSteps walks through steps one by one, each in its own review or
+// in the purge TUI, and applies the chosen actions after every step. Tasks
+// left alone in the purge TUI are ignored. Progress is kept in the state file
+// progressFile until the review is complete.
+func runReviewSteps(taskManager adapters.TaskManagerAdapter, name string, progressFile string, steps []reviewStep, restart bool) error {
+	progressPath, err := adapters.GetStateFilePath(progressFile)
+	if err != nil {
+		return err
+	}
+	progress := &reviewProgress{StartedAt: time.Now()}
+	if !restart {
+		saved, err := loadReviewProgress(progressPath)
+		if err != nil {
+			return err
+		}
+		if saved != nil {
+			progress = saved
+			fmt.Printf("Resuming %s review started on %s\n", name, progress.StartedAt.Format("2006-01-02 15:04"))
+		}
+	}
+
+	columns, err := resolveColumns(reviewColumns)
+	if err != nil {
+		return err
+	}
+
+	for i, step := range steps {
+		if slices.Contains(progress.CompletedSteps, step.Name) {
+			continue
+		}
+
+		title := fmt.Sprintf("Step %d/%d: %s", i+1, len(steps), step.Title)
+		if step.Run != nil {
+			fmt.Println(title)
+			err = step.Run(taskManager)
+		} else {
+			err = reviewStepTasks(taskManager, &step, title, columns)
+		}
+		if err != nil {
+			return err
+		}
+
+		progress.CompletedSteps = append(progress.CompletedSteps, step.Name)
+		if err := saveReviewProgress(progressPath, progress); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Remove(progressPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	fmt.Printf("%s review complete\n", name)
+	return nil
+}
+
+func reviewStepTasks(taskManager adapters.TaskManagerAdapter, step *reviewStep, title string, columns []taskColumn) error {
+	tasks, err := taskManager.FetchTasks()
+	if err != nil {
+		return err
+	}
+	selected, err := step.Select(&tasks)
+	if err != nil {
+		return err
+	}
+	if len(selected) == 0 {
+		fmt.Printf("%s - nothing to review\n", title)
+		return nil
+	}
+
+	if err := taskmanager.SortTasks(&selected, []string{"project", "updated"}); err != nil {
+		return err
+	}
+	actions, err := reviewTasks(&selected, columns, title, step.Actions, adapters.ActionIgnore)
+	if err != nil {
+		return err
+	}
+	applied, err := SavePurge(taskManager, &actions)
+	if err != nil {
+		return err
+	}
+	if applied && step.Archiver != "" {
+		return archiveActions(step.Archiver, step.Title, &actions)
+	}
+	return nil
+}
+
+func archiveActions(name string, title string, actions *[]adapters.TaskAction) error {
+	archiverAdapter, err := archiver.Initialize(name, settings)
+	if err != nil {
+		return err
+	}
+	return archiverAdapter.Archive(title, actions)
+}
+
+func loadReviewProgress(path string) (*reviewProgress, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var progress reviewProgress
+	if err := json.Unmarshal(data, &progress); err != nil {
+		return nil, fmt.Errorf("corrupt review progress in %s: %w", path, err)
+	}
+	return &progress, nil
+}
+
+func saveReviewProgress(path string, progress *reviewProgress) error {
+	data, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+func selectByFilter(filterRequest adapters.FilterRequest) func(tasks *[]adapters.Task) ([]adapters.Task, error) {
+	return func(tasks *[]adapters.Task) ([]adapters.Task, error) {
+		return taskmanager.FilterTasks(tasks, &filterRequest)
+	}
+}
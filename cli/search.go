@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"github.com/dormunis/gitd/search"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const searchIndexFile = "search-index.json"
+
+// searchArchiveHistory is how far back completed tasks are indexed when the
+// index is first built.
+var searchArchiveHistory = adapters.TimeSpan{Years: 1}
+
+type searchMatch struct {
+	ID      string    `json:"id"`
+	Content string    `json:"content"`
+	Project string    `json:"project"`
+	State   string    `json:"state"`
+	Date    time.Time `json:"date"`
+	Score   float64   `json:"score"`
+}
+
+// Search brings the local index up to date with the task manager, then
+// prints the tasks best matching text.
+func Search(taskManager adapters.TaskManagerAdapter, text string, limit int, rebuild bool, format outputFormat) error {
+	matches, err := searchTasks(taskManager, text, limit, rebuild, 0)
+	if err != nil {
+		return err
+	}
+	return writeOutput(format, matches, func() { printMatches(matches, time.Now()) })
+}
+
+// searchTasks refreshes the index from the task manager, unless it was
+// updated less than maxAge ago, and searches it.
+func searchTasks(taskManager adapters.TaskManagerAdapter, text string, limit int, rebuild bool, maxAge time.Duration) ([]searchMatch, error) {
+	path, err := adapters.GetStateFilePath(searchIndexFile)
+	if err != nil {
+		return nil, err
+	}
+	index := search.NewIndex()
+	if !rebuild {
+		index, err = search.Load(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if now := time.Now(); now.Sub(index.UpdatedAt) >= maxAge {
+		if err := refreshIndex(taskManager, index, now); err != nil {
+			return nil, err
+		}
+		if err := index.Save(path); err != nil {
+			return nil, err
+		}
+	}
+
+	results := index.Search(text, limit)
+	matches := make([]searchMatch, len(results))
+	for i, result := range results {
+		matches[i] = searchMatch{
+			ID:      result.Document.ID,
+			Content: result.Document.Content,
+			Project: result.Document.Project,
+			State:   "active",
+			Date:    result.Document.Date,
+			Score:   result.Score,
+		}
+		if result.Document.Archived {
+			matches[i].State = "completed"
+		}
+	}
+	return matches, nil
+}
+
+func refreshIndex(taskManager adapters.TaskManagerAdapter, index *search.Index, now time.Time) error {
+	since := index.UpdatedAt
+	if since.IsZero() {
+		since = searchArchiveHistory.ModifyDate(now, false)
+	}
+	// Archived tasks go first, so that tasks completed since the last update
+	// keep the description and notes indexed while they were current.
+	completed, err := taskManager.FetchCompletedTasks(since, now)
+	if err != nil {
+		return err
+	}
+	index.AddArchived(&completed)
+	tasks, err := taskManager.FetchTasks()
+	if err != nil {
+		return err
+	}
+	index.SyncTasks(&tasks)
+	index.UpdatedAt = now
+	return nil
+}
+
+func printMatches(matches []searchMatch, now time.Time) {
+	if len(matches) == 0 {
+		fmt.Println("No matches found")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join([]string{"Task", "Project", "State", "Age"}, "\t"))
+	for _, match := range matches {
+		fmt.Fprintln(w, strings.Join([]string{
+			match.Content,
+			match.Project,
+			match.State,
+			formatAge(now.Sub(match.Date)),
+		}, "\t"))
+	}
+	w.Flush()
+}
+
+func formatAge(age time.Duration) string {
+	days := int(age.Hours() / 24)
+	switch {
+	case days < 1:
+		return "today"
+	case days < 14:
+		return fmt.Sprintf("%d days ago", days)
+	case days < 60:
+		return fmt.Sprintf("%d weeks ago", days/7)
+	case days < 730:
+		return fmt.Sprintf("%d months ago", days/30)
+	default:
+		return fmt.Sprintf("%d years ago", days/365)
+	}
+}
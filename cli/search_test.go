@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"github.com/dormunis/gitd/adapters"
+	"testing"
+	"time"
+)
+
+// countingTaskManager counts how often tasks are fetched.
+type countingTaskManager struct {
+	*memoryTaskManager
+	fetches int
+}
+
+func (c *countingTaskManager) FetchTasks() ([]adapters.Task, error) {
+	c.fetches++
+	return c.memoryTaskManager.FetchTasks()
+}
+
+func TestSearchTasksRefresh(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	taskManager := &countingTaskManager{memoryTaskManager: newMemoryTaskManager("memory", time.Now())}
+	taskManager.add("Renew passport", "Personal")
+
+	tests := []struct {
+		name    string
+		rebuild bool
+		maxAge  time.Duration
+		fetches int
+	}{
+		{"first search builds the index", false, time.Minute, 1},
+		{"recent index is trusted", false, time.Minute, 1},
+		{"search always refreshes", false, 0, 2},
+		{"rebuild ignores the index", true, time.Minute, 3},
+	}
+	for _, tt := range tests {
+		matches, err := searchTasks(taskManager, "passport", 10, tt.rebuild, tt.maxAge)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(matches) != 1 {
+			t.Errorf("%s: got %d matches, want 1", tt.name, len(matches))
+		}
+		if taskManager.fetches != tt.fetches {
+			t.Errorf("%s: got %d fetches, want %d", tt.name, taskManager.fetches, tt.fetches)
+		}
+	}
+}
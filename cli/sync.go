@@ -0,0 +1,549 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+type SyncOptions struct {
+	From   string // name of the task manager tasks are copied from
+	To     string
+	TwoWay bool // also copy tasks and changes from To back to From
+	DryRun bool // report the changes without making them
+}
+
+// syncSide is one of the task managers being synced, with its tasks as they
+// were fetched.
+type syncSide struct {
+	Name        string
+	TaskManager adapters.TaskManagerAdapter
+	Tasks       []adapters.Task
+	Projects    []adapters.Project
+	Location    *time.Location // the user's timezone, which due dates are written in
+	byID        map[string]*adapters.Task
+}
+
+type syncChange struct {
+	Action   string   `json:"action"` // create, update, complete, link or unlink
+	From     string   `json:"from"`
+	To       string   `json:"to"`
+	Content  string   `json:"content"`
+	FromID   string   `json:"from_id"`
+	ToID     string   `json:"to_id,omitempty"`
+	Fields   []string `json:"fields,omitempty"`   // fields copied by an update
+	Conflict string   `json:"conflict,omitempty"` // set when both sides changed
+
+	source *adapters.Task
+	target *adapters.Task
+}
+
+// Sync copies the tasks of one task manager to another, and with TwoWay back,
+// remembering which tasks are the same in a state file. Tasks are matched by
+// content and project the first time, so that syncing into a task manager
+// already holding some of them does not duplicate them.
+//
+// A side changed since the last sync is copied over the other; when both
+// changed, the more recently updated one wins and the conflict is reported.
+// A task completed or deleted on one side is completed on the other.
+func Sync(from, to adapters.TaskManagerAdapter, options SyncOptions, format outputFormat) error {
+	if options.From == options.To {
+		return adapters.Errorf(adapters.ErrorValidation, "cannot sync %s with itself", options.From)
+	}
+	state, err := loadSyncState(options.From, options.To)
+	if err != nil {
+		return err
+	}
+
+	a := &syncSide{Name: options.From, TaskManager: from}
+	b := &syncSide{Name: options.To, TaskManager: to}
+	for _, side := range []*syncSide{a, b} {
+		if err := side.fetch(); err != nil {
+			return err
+		}
+	}
+
+	changes, links := reconcile(state, a, b, options.TwoWay)
+	if options.DryRun {
+		return writeOutput(format, changes, func() { printSync(changes, true) })
+	}
+
+	existing := make(map[string][]string) // tasks each side had before any was created
+	for _, side := range []*syncSide{a, b} {
+		for _, task := range side.Tasks {
+			existing[side.Name] = append(existing[side.Name], task.ID)
+		}
+	}
+	for _, target := range []*syncSide{a, b} {
+		if err := applySync(changes, target); err != nil {
+			return err
+		}
+	}
+
+	for _, side := range []*syncSide{a, b} {
+		if err := side.fetch(); err != nil {
+			return err
+		}
+	}
+	state.Links = relink(links, changes, a, b, existing)
+	state.SyncedAt = time.Now()
+	if err := saveSyncState(state); err != nil {
+		return err
+	}
+	return writeOutput(format, changes, func() { printSync(changes, false) })
+}
+
+func (s *syncSide) fetch() error {
+	if s.Location == nil {
+		now, err := userNow(s.TaskManager)
+		if err != nil {
+			return err
+		}
+		s.Location = now.Location()
+	}
+	tasks, err := s.TaskManager.FetchTasks()
+	if err != nil {
+		return err
+	}
+	projects, err := s.TaskManager.FetchProjects()
+	if err != nil {
+		return err
+	}
+	s.Tasks, s.Projects = tasks, projects
+	s.byID = make(map[string]*adapters.Task)
+	for i := range s.Tasks {
+		s.byID[s.Tasks[i].ID] = &s.Tasks[i]
+	}
+	return nil
+}
+
+// projectID finds the project of the given name, empty for the inbox.
+func (s *syncSide) projectID(name string) (string, bool) {
+	if name == "" {
+		return "", true
+	}
+	for _, project := range s.Projects {
+		if !project.Archived && !project.Inbox && importKey(project.Name) == importKey(name) {
+			return project.ID, true
+		}
+	}
+	return "", false
+}
+
+// reconcile decides what to copy where, returning the links to keep.
+func reconcile(state *adapters.SyncState, a, b *syncSide, twoWay bool) ([]syncChange, []adapters.SyncLink) {
+	changes := []syncChange{}
+	var links []adapters.SyncLink
+	linked := map[string]map[string]bool{a.Name: {}, b.Name: {}}
+	for _, link := range state.Links {
+		linked[a.Name][link.ID(a.Name)] = true
+		linked[b.Name][link.ID(b.Name)] = true
+		taskA, okA := a.byID[link.ID(a.Name)]
+		taskB, okB := b.byID[link.ID(b.Name)]
+		snapshotA, snapshotB := link.Tasks[a.Name], link.Tasks[b.Name]
+		changedA := okA && taskA.Fingerprint() != snapshotA.Fingerprint()
+		changedB := okB && taskB.Fingerprint() != snapshotB.Fingerprint()
+
+		switch {
+		case !okA && !okB:
+			continue
+		case !okA && twoWay && changedB:
+			changes = append(changes, syncConflict(a, b, &snapshotA, taskB))
+			delete(linked[b.Name], taskB.ID)
+			continue
+		case !okA:
+			changes = append(changes, syncComplete(a, b, &snapshotA, taskB))
+		case !okB && !twoWay:
+			// closed in the copy only, which is left as it is
+		case !okB && changedA:
+			changes = append(changes, syncConflict(b, a, &snapshotB, taskA))
+			delete(linked[a.Name], taskA.ID)
+			continue
+		case !okB:
+			changes = append(changes, syncComplete(b, a, &snapshotB, taskA))
+		case changedA && changedB && twoWay:
+			if len(syncDiff(taskA, taskB, b.Location)) == 0 {
+				break
+			}
+			source, target, winner, loser := taskA, taskB, a, b
+			if taskB.UpdatedDate.After(taskA.UpdatedDate) {
+				source, target, winner, loser = taskB, taskA, b, a
+			}
+			change := syncUpdate(winner, loser, source, target)
+			change.Conflict = fmt.Sprintf("changed in both, kept the version from %s updated %s", winner.Name, source.UpdatedDate.In(winner.Location).Format("2006-01-02 15:04"))
+			changes = append(changes, change)
+		case changedA:
+			if len(syncDiff(taskA, taskB, b.Location)) > 0 {
+				changes = append(changes, syncUpdate(a, b, taskA, taskB))
+			}
+		case changedB && twoWay:
+			if len(syncDiff(taskB, taskA, a.Location)) > 0 {
+				changes = append(changes, syncUpdate(b, a, taskB, taskA))
+			}
+		}
+		links = append(links, link)
+	}
+
+	changes = append(changes, newTaskChanges(a, b, linked, &links)...)
+	if twoWay {
+		changes = append(changes, newTaskChanges(b, a, linked, &links)...)
+	}
+	return changes, links
+}
+
+// newTaskChanges creates the tasks of source that are not linked yet in
+// target, linking them instead to an unlinked task of the same content and
+// project when there is one, which source then updates.
+func newTaskChanges(source, target *syncSide, linked map[string]map[string]bool, links *[]adapters.SyncLink) []syncChange {
+	var changes []syncChange
+	for i := range source.Tasks {
+		task := &source.Tasks[i]
+		if linked[source.Name][task.ID] {
+			continue
+		}
+		index := slices.IndexFunc(target.Tasks, func(other adapters.Task) bool {
+			return !linked[target.Name][other.ID] && importKey(other.Content) == importKey(task.Content) &&
+				importKey(syncProject(&other)) == importKey(syncProject(task))
+		})
+		if index == -1 {
+			changes = append(changes, syncChange{Action: "create", From: source.Name, To: target.Name, Content: task.Content, FromID: task.ID, source: task})
+			continue
+		}
+
+		match := &target.Tasks[index]
+		linked[source.Name][task.ID] = true
+		linked[target.Name][match.ID] = true
+		*links = append(*links, adapters.SyncLink{Tasks: map[string]adapters.Task{source.Name: *task, target.Name: *match}})
+		if len(syncDiff(task, match, target.Location)) > 0 {
+			changes = append(changes, syncUpdate(source, target, task, match))
+		} else {
+			changes = append(changes, syncChange{Action: "link", From: source.Name, To: target.Name, Content: task.Content, FromID: task.ID, ToID: match.ID})
+		}
+	}
+	return changes
+}
+
+func syncUpdate(from, to *syncSide, source, target *adapters.Task) syncChange {
+	return syncChange{
+		Action:  "update",
+		From:    from.Name,
+		To:      to.Name,
+		Content: source.Content,
+		FromID:  source.ID,
+		ToID:    target.ID,
+		Fields:  syncDiff(source, target, to.Location),
+		source:  source,
+		target:  target,
+	}
+}
+
+func syncComplete(closed, open *syncSide, snapshot, task *adapters.Task) syncChange {
+	return syncChange{
+		Action:  "complete",
+		From:    closed.Name,
+		To:      open.Name,
+		Content: task.Content,
+		FromID:  snapshot.ID,
+		ToID:    task.ID,
+		source:  snapshot,
+		target:  task,
+	}
+}
+
+// syncConflict unlinks a task closed in one task manager but changed in the
+// other since, keeping the changes. A two-way sync copies it back as new.
+func syncConflict(closed, changed *syncSide, snapshot, task *adapters.Task) syncChange {
+	return syncChange{
+		Action:   "unlink",
+		From:     closed.Name,
+		To:       changed.Name,
+		Content:  task.Content,
+		FromID:   snapshot.ID,
+		ToID:     task.ID,
+		Conflict: fmt.Sprintf("closed in %s but changed in %s, kept in %s", closed.Name, changed.Name, changed.Name),
+	}
+}
+
+// syncDiff names the fields of target that differ from source. A due date
+// removed from source is not copied, as task managers differ in how it is
+// cleared. Due dates are compared as written in location.
+func syncDiff(source, target *adapters.Task, location *time.Location) []string {
+	var fields []string
+	if source.Content != target.Content {
+		fields = append(fields, "content")
+	}
+	if source.Description != target.Description {
+		fields = append(fields, "description")
+	}
+	if importKey(syncProject(source)) != importKey(syncProject(target)) {
+		fields = append(fields, "project")
+	}
+	if len(syncTags(source.Tags, target.Tags)) > 0 || len(syncTags(target.Tags, source.Tags)) > 0 {
+		fields = append(fields, "tags")
+	}
+	if source.Status != target.Status {
+		fields = append(fields, "status")
+	}
+	if source.Priority != target.Priority {
+		fields = append(fields, "priority")
+	}
+	if source.DueDate != nil && (target.DueDate == nil || syncDue(source.DueDate, location) != syncDue(target.DueDate, location)) {
+		fields = append(fields, "due")
+	}
+	return fields
+}
+
+// applySync makes the changes bound for target, creating the projects they
+// refer to first.
+func applySync(changes []syncChange, target *syncSide) error {
+	var newProjects []string
+	for _, change := range changes {
+		if change.To != target.Name || (change.Action != "create" && !slices.Contains(change.Fields, "project")) {
+			continue
+		}
+		name := syncProject(change.source)
+		if _, ok := target.projectID(name); !ok && !slices.ContainsFunc(newProjects, func(other string) bool { return importKey(other) == importKey(name) }) {
+			newProjects = append(newProjects, name)
+		}
+	}
+	if len(newProjects) > 0 {
+		projectCreator, ok := target.TaskManager.(adapters.ProjectCreator)
+		if !ok {
+			return adapters.Errorf(adapters.ErrorValidation, "%s cannot create projects, missing: %s", target.Name, strings.Join(newProjects, ", "))
+		}
+		created, err := projectCreator.CreateProjects(newProjects)
+		if err != nil {
+			return err
+		}
+		target.Projects = append(target.Projects, created...)
+	}
+
+	var edits []adapters.TaskEdit
+	var drafts []adapters.TaskDraft
+	for _, change := range changes {
+		if change.To != target.Name {
+			continue
+		}
+		switch change.Action {
+		case "create":
+			drafts = append(drafts, syncDraft(change.source, target))
+		case "update":
+			edits = append(edits, syncEdit(change.source, change.target, change.Fields, target))
+		case "complete":
+			edits = append(edits, adapters.TaskEdit{Task: change.target, Complete: true})
+		}
+	}
+	if len(edits) > 0 {
+		if err := target.TaskManager.EditTasks(&edits); err != nil {
+			return err
+		}
+	}
+	if len(drafts) > 0 {
+		return target.TaskManager.CreateTasks(&drafts)
+	}
+	return nil
+}
+
+func syncDraft(source *adapters.Task, target *syncSide) adapters.TaskDraft {
+	projectID, _ := target.projectID(syncProject(source))
+	draft := adapters.TaskDraft{
+		Content:     source.Content,
+		Description: source.Description,
+		ProjectID:   projectID,
+		Tags:        source.Tags,
+		Priority:    source.Priority,
+	}
+	if source.DueDate != nil {
+		due := syncDue(source.DueDate, target.Location)
+		draft.Due = &due
+	}
+	return draft
+}
+
+func syncEdit(source, target *adapters.Task, fields []string, side *syncSide) adapters.TaskEdit {
+	edit := adapters.TaskEdit{Task: target}
+	for _, field := range fields {
+		switch field {
+		case "content":
+			edit.Content = &source.Content
+		case "description":
+			edit.Description = &source.Description
+		case "project":
+			projectID, _ := side.projectID(syncProject(source))
+			edit.ProjectID = &projectID
+		case "tags":
+			edit.AddTags = syncTags(source.Tags, target.Tags)
+			edit.RemoveTags = syncTags(target.Tags, source.Tags)
+		case "status":
+			edit.Status = &source.Status
+		case "priority":
+			edit.Priority = &source.Priority
+		case "due":
+			due := syncDue(source.DueDate, side.Location)
+			edit.Due = &due
+		}
+	}
+	return edit
+}
+
+// relink refreshes the snapshots of the links once the changes are made, and
+// links the tasks created to their source. Links whose task is gone on both
+// sides are dropped.
+func relink(links []adapters.SyncLink, changes []syncChange, a, b *syncSide, existing map[string][]string) []adapters.SyncLink {
+	var kept []adapters.SyncLink
+	linked := map[string]map[string]bool{a.Name: {}, b.Name: {}}
+	for _, link := range links {
+		present := false
+		for _, side := range []*syncSide{a, b} {
+			linked[side.Name][link.ID(side.Name)] = true
+			if task, ok := side.byID[link.ID(side.Name)]; ok {
+				link.Tasks[side.Name] = *task
+				present = true
+			}
+		}
+		if present {
+			kept = append(kept, link)
+		}
+	}
+
+	sides := map[string]*syncSide{a.Name: a, b.Name: b}
+	for _, change := range changes {
+		if change.Action != "create" {
+			continue
+		}
+		source, target := sides[change.From], sides[change.To]
+		task, ok := source.byID[change.FromID]
+		if !ok {
+			task = change.source
+		}
+		index := slices.IndexFunc(target.Tasks, func(other adapters.Task) bool {
+			return !linked[target.Name][other.ID] && !slices.Contains(existing[target.Name], other.ID) &&
+				importKey(other.Content) == importKey(task.Content)
+		})
+		if index == -1 {
+			fmt.Fprintf(os.Stderr, "Could not find %q in %s after creating it, it may be copied again\n", task.Content, target.Name)
+			continue
+		}
+		linked[target.Name][target.Tasks[index].ID] = true
+		kept = append(kept, adapters.SyncLink{Tasks: map[string]adapters.Task{source.Name: *task, target.Name: target.Tasks[index]}})
+	}
+	return kept
+}
+
+func printSync(changes []syncChange, dryRun bool) {
+	var conflicts []syncChange
+	count := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, change := range changes {
+		if change.Conflict != "" {
+			conflicts = append(conflicts, change)
+		}
+		if change.Action == "unlink" {
+			continue
+		}
+		if count == 0 {
+			fmt.Fprintln(w, "ACTION\tFROM\tTO\tTASK\tFIELDS")
+		}
+		count++
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", change.Action, change.From, change.To, change.Content, strings.Join(change.Fields, ", "))
+	}
+	w.Flush()
+
+	if len(conflicts) > 0 {
+		fmt.Println("\nConflicts:")
+		for _, conflict := range conflicts {
+			fmt.Printf("  %s: %s", conflict.Content, conflict.Conflict)
+			if len(conflict.Fields) > 0 {
+				fmt.Printf(", overwriting %s in %s", strings.Join(conflict.Fields, ", "), conflict.To)
+			}
+			fmt.Println()
+		}
+	}
+
+	switch {
+	case count == 0 && len(conflicts) == 0:
+		fmt.Println("Already in sync")
+	case dryRun:
+		fmt.Printf("\n%d changes and %d conflicts, nothing was changed (dry run)\n", count, len(conflicts))
+	default:
+		fmt.Printf("\n%d changes and %d conflicts\n", count, len(conflicts))
+	}
+}
+
+// syncProject is the name of the task's project, empty for the inbox.
+func syncProject(task *adapters.Task) string {
+	if task.Inbox {
+		return ""
+	}
+	return task.Project
+}
+
+// syncTags returns the tags missing from other, regardless of case.
+func syncTags(tags, other []string) []string {
+	var missing []string
+	for _, tag := range tags {
+		if !slices.ContainsFunc(other, func(o string) bool { return strings.EqualFold(o, tag) }) {
+			missing = append(missing, tag)
+		}
+	}
+	return missing
+}
+
+// syncDue writes a due date for a task manager whose user is in location.
+func syncDue(due *time.Time, location *time.Location) string {
+	hour, minute, second := due.Clock()
+	if hour == 0 && minute == 0 && second == 0 {
+		return due.Format("2006-01-02")
+	}
+	return due.In(location).Format("2006-01-02 15:04")
+}
+
+// syncStateFile is the same whichever way round the task managers are synced.
+func syncStateFile(from, to string) (string, error) {
+	names := []string{from, to}
+	slices.Sort(names)
+	return adapters.GetStateFilePath(fmt.Sprintf("sync-%s-%s.json", names[0], names[1]))
+}
+
+func loadSyncState(from, to string) (*adapters.SyncState, error) {
+	path, err := syncStateFile(from, to)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		names := []string{from, to}
+		slices.Sort(names)
+		return &adapters.SyncState{TaskManagers: names}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state adapters.SyncState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("corrupt sync state in %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+func saveSyncState(state *adapters.SyncState) error {
+	path, err := syncStateFile(state.TaskManagers[0], state.TaskManagers[1])
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
@@ -0,0 +1,411 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+// memoryTaskManager is a task manager kept in memory, for syncing without
+// a network.
+type memoryTaskManager struct {
+	name     string
+	tasks    []adapters.Task
+	projects []adapters.Project
+	closed   []string
+	now      time.Time
+	lastID   int
+}
+
+func newMemoryTaskManager(name string, now time.Time) *memoryTaskManager {
+	return &memoryTaskManager{name: name, now: now}
+}
+
+func (m *memoryTaskManager) add(content, project string) *adapters.Task {
+	m.lastID++
+	task := adapters.Task{
+		ID:          fmt.Sprintf("%s-%d", m.name, m.lastID),
+		Content:     content,
+		Tags:        []string{},
+		Status:      adapters.StatusActive,
+		Priority:    adapters.PriorityLow,
+		CreatedDate: m.now,
+		UpdatedDate: m.now,
+		TaskManger:  m.name,
+	}
+	if project == "" {
+		task.Inbox, task.Project = true, "Inbox"
+	} else {
+		task.ProjectID, task.Project = m.project(project), project
+	}
+	m.tasks = append(m.tasks, task)
+	return &m.tasks[len(m.tasks)-1]
+}
+
+func (m *memoryTaskManager) project(name string) string {
+	for _, project := range m.projects {
+		if project.Name == name {
+			return project.ID
+		}
+	}
+	created, _ := m.CreateProjects([]string{name})
+	return created[0].ID
+}
+
+func (m *memoryTaskManager) task(content string) *adapters.Task {
+	index := slices.IndexFunc(m.tasks, func(task adapters.Task) bool { return task.Content == content })
+	if index == -1 {
+		return nil
+	}
+	return &m.tasks[index]
+}
+
+// touch moves the clock on and marks the task as updated then.
+func (m *memoryTaskManager) touch(task *adapters.Task, after time.Duration) {
+	m.now = m.now.Add(after)
+	task.UpdatedDate = m.now
+}
+
+func (m *memoryTaskManager) Initialize(adapters.Settings) error { return nil }
+
+func (m *memoryTaskManager) FetchTasks() ([]adapters.Task, error) {
+	tasks := make([]adapters.Task, len(m.tasks))
+	for i, task := range m.tasks {
+		task.Tags = slices.Clone(task.Tags)
+		tasks[i] = task
+	}
+	return tasks, nil
+}
+
+// Location is the timezone of now, in which due dates are parsed.
+func (m *memoryTaskManager) Location() (*time.Location, error) {
+	return m.now.Location(), nil
+}
+
+func (m *memoryTaskManager) FetchProjects() ([]adapters.Project, error) {
+	return slices.Clone(m.projects), nil
+}
+
+func (m *memoryTaskManager) FetchCompletedTasks(time.Time, time.Time) ([]adapters.Task, error) {
+	return nil, nil
+}
+
+func (m *memoryTaskManager) UpdateTasks(*[]adapters.TaskAction) error { return nil }
+
+func (m *memoryTaskManager) EditTasks(edits *[]adapters.TaskEdit) error {
+	for _, edit := range *edits {
+		index := slices.IndexFunc(m.tasks, func(task adapters.Task) bool { return task.ID == edit.Task.ID })
+		if index == -1 {
+			return fmt.Errorf("no task %s in %s", edit.Task.ID, m.name)
+		}
+		task := &m.tasks[index]
+		if edit.Complete || edit.Delete {
+			m.closed = append(m.closed, task.Content)
+			m.tasks = slices.Delete(m.tasks, index, index+1)
+			continue
+		}
+		if edit.Content != nil {
+			task.Content = *edit.Content
+		}
+		if edit.Description != nil {
+			task.Description = *edit.Description
+		}
+		if edit.ProjectID != nil {
+			task.ProjectID = *edit.ProjectID
+			task.Project = m.projectName(task.ProjectID)
+			task.Inbox = task.ProjectID == ""
+		}
+		task.Tags = slices.DeleteFunc(task.Tags, func(tag string) bool { return slices.Contains(edit.RemoveTags, tag) })
+		task.Tags = append(task.Tags, edit.AddTags...)
+		if edit.Status != nil {
+			task.Status = *edit.Status
+		}
+		if edit.Priority != nil {
+			task.Priority = *edit.Priority
+		}
+		if edit.Due != nil {
+			due, err := adapters.ParseDate(*edit.Due, m.now)
+			if err != nil {
+				return err
+			}
+			task.DueDate = &due
+		}
+		task.UpdatedDate = m.now
+	}
+	return nil
+}
+
+func (m *memoryTaskManager) projectName(id string) string {
+	for _, project := range m.projects {
+		if project.ID == id {
+			return project.Name
+		}
+	}
+	return "Inbox"
+}
+
+func (m *memoryTaskManager) CreateTasks(drafts *[]adapters.TaskDraft) error {
+	for _, draft := range *drafts {
+		task := m.add(draft.Content, m.projectName(draft.ProjectID))
+		if draft.ProjectID == "" {
+			task.Inbox, task.Project, task.ProjectID = true, "Inbox", ""
+		}
+		task.Description = draft.Description
+		task.Tags = append(task.Tags, draft.Tags...)
+		if draft.Priority != 0 {
+			task.Priority = draft.Priority
+		}
+		if draft.Due != nil {
+			due, err := adapters.ParseDate(*draft.Due, m.now)
+			if err != nil {
+				return err
+			}
+			task.DueDate = &due
+		}
+	}
+	return nil
+}
+
+func (m *memoryTaskManager) CreateProjects(names []string) ([]adapters.Project, error) {
+	var created []adapters.Project
+	for _, name := range names {
+		m.lastID++
+		project := adapters.Project{ID: fmt.Sprintf("%s-p%d", m.name, m.lastID), Name: name}
+		m.projects = append(m.projects, project)
+		created = append(created, project)
+	}
+	return created, nil
+}
+
+func (m *memoryTaskManager) ArchiveProjects(*[]adapters.Project) error { return nil }
+
+// syncBoth runs a sync of a and b and returns the changes it made.
+func syncBoth(t *testing.T, a, b *memoryTaskManager, twoWay bool) []syncChange {
+	t.Helper()
+	state, err := loadSyncState(a.name, b.name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sideA := &syncSide{Name: a.name, TaskManager: a}
+	sideB := &syncSide{Name: b.name, TaskManager: b}
+	for _, side := range []*syncSide{sideA, sideB} {
+		if err := side.fetch(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	changes, _ := reconcile(state, sideA, sideB, twoWay)
+
+	stdout := os.Stdout
+	os.Stdout, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	defer func() { os.Stdout = stdout }()
+	if err := Sync(a, b, SyncOptions{From: a.name, To: b.name, TwoWay: twoWay}, outputJSON); err != nil {
+		t.Fatal(err)
+	}
+	return changes
+}
+
+func changeSummary(changes []syncChange) []string {
+	var summary []string
+	for _, change := range changes {
+		line := fmt.Sprintf("%s %s>%s %s", change.Action, change.From, change.To, change.Content)
+		if len(change.Fields) > 0 {
+			line += fmt.Sprintf(" %v", change.Fields)
+		}
+		if change.Conflict != "" {
+			line += " (conflict)"
+		}
+		summary = append(summary, line)
+	}
+	return summary
+}
+
+func TestSync(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		twoWay bool
+		setup  func(a, b *memoryTaskManager)
+		change func(a, b *memoryTaskManager)
+		want   []string
+		check  func(t *testing.T, a, b *memoryTaskManager)
+	}{
+		{
+			name: "first sync links matching tasks and creates the others",
+			setup: func(a, b *memoryTaskManager) {
+				a.add("Buy milk", "")
+				a.add("Fix the roof", "Home")
+				b.add("fix the ROOF", "home")
+			},
+			want: []string{"create a>b Buy milk", "update a>b Fix the roof [content]"},
+			check: func(t *testing.T, a, b *memoryTaskManager) {
+				if task := b.task("Buy milk"); task == nil || !task.Inbox {
+					t.Errorf("got %+v in b, want Buy milk in the inbox", task)
+				}
+				if len(b.tasks) != 2 {
+					t.Errorf("got %d tasks in b, want 2", len(b.tasks))
+				}
+			},
+		},
+		{
+			name: "creates missing projects",
+			setup: func(a, b *memoryTaskManager) {
+				task := a.add("Paint the fence", "Garden")
+				task.Tags = []string{"weekend"}
+				task.Priority = adapters.PriorityHigh
+			},
+			want: []string{"create a>b Paint the fence"},
+			check: func(t *testing.T, a, b *memoryTaskManager) {
+				task := b.task("Paint the fence")
+				if task == nil || task.Project != "Garden" || !slices.Equal(task.Tags, []string{"weekend"}) || task.Priority != adapters.PriorityHigh {
+					t.Errorf("got %+v in b", task)
+				}
+			},
+		},
+		{
+			name:  "copies changes of the source",
+			setup: func(a, b *memoryTaskManager) { a.add("Call mum", "") },
+			change: func(a, b *memoryTaskManager) {
+				task := a.task("Call mum")
+				task.Content = "Call mum and dad"
+				task.Tags = []string{"phone"}
+				a.touch(task, time.Hour)
+			},
+			want: []string{"update a>b Call mum and dad [content tags]"},
+			check: func(t *testing.T, a, b *memoryTaskManager) {
+				if task := b.task("Call mum and dad"); task == nil || !slices.Equal(task.Tags, []string{"phone"}) {
+					t.Errorf("got %+v in b", task)
+				}
+			},
+		},
+		{
+			name:  "one-way leaves changes of the copy",
+			setup: func(a, b *memoryTaskManager) { a.add("Call mum", "") },
+			change: func(a, b *memoryTaskManager) {
+				task := b.task("Call mum")
+				task.Priority = adapters.PriorityCritical
+				b.touch(task, time.Hour)
+			},
+			want: nil,
+		},
+		{
+			name:   "two-way copies changes of the copy back",
+			twoWay: true,
+			setup:  func(a, b *memoryTaskManager) { a.add("Call mum", "") },
+			change: func(a, b *memoryTaskManager) {
+				task := b.task("Call mum")
+				task.Priority = adapters.PriorityCritical
+				b.touch(task, time.Hour)
+			},
+			want: []string{"update b>a Call mum [priority]"},
+			check: func(t *testing.T, a, b *memoryTaskManager) {
+				if task := a.task("Call mum"); task.Priority != adapters.PriorityCritical {
+					t.Errorf("got priority %v in a", task.Priority)
+				}
+			},
+		},
+		{
+			name:   "two-way conflict keeps the latest change",
+			twoWay: true,
+			setup:  func(a, b *memoryTaskManager) { a.add("Call mum", "") },
+			change: func(a, b *memoryTaskManager) {
+				taskA := a.task("Call mum")
+				taskA.Description = "about the holidays"
+				a.touch(taskA, time.Hour)
+				taskB := b.task("Call mum")
+				taskB.Description = "about the birthday"
+				b.now = a.now
+				b.touch(taskB, time.Hour)
+			},
+			want: []string{"update b>a Call mum [description] (conflict)"},
+			check: func(t *testing.T, a, b *memoryTaskManager) {
+				if task := a.task("Call mum"); task.Description != "about the birthday" {
+					t.Errorf("got description %q in a", task.Description)
+				}
+			},
+		},
+		{
+			name: "writes due dates in the timezone of the target",
+			setup: func(a, b *memoryTaskManager) {
+				b.now = b.now.In(time.FixedZone("UTC+2", 2*60*60))
+				due := time.Date(2026, 3, 2, 18, 30, 0, 0, time.UTC)
+				a.add("Call mum", "").DueDate = &due
+			},
+			want: []string{"create a>b Call mum"},
+			check: func(t *testing.T, a, b *memoryTaskManager) {
+				if task := b.task("Call mum"); task.DueDate == nil || !task.DueDate.Equal(*a.task("Call mum").DueDate) {
+					t.Errorf("got due date %v in b, want %v", task.DueDate, a.task("Call mum").DueDate)
+				}
+			},
+		},
+		{
+			name:  "completes tasks closed in the source",
+			setup: func(a, b *memoryTaskManager) { a.add("Call mum", "") },
+			change: func(a, b *memoryTaskManager) {
+				edits := []adapters.TaskEdit{{Task: a.task("Call mum"), Complete: true}}
+				a.EditTasks(&edits)
+			},
+			want: []string{"complete a>b Call mum"},
+			check: func(t *testing.T, a, b *memoryTaskManager) {
+				if !slices.Equal(b.closed, []string{"Call mum"}) {
+					t.Errorf("got %v closed in b", b.closed)
+				}
+			},
+		},
+		{
+			name:  "one-way leaves tasks closed in the copy",
+			setup: func(a, b *memoryTaskManager) { a.add("Call mum", "") },
+			change: func(a, b *memoryTaskManager) {
+				edits := []adapters.TaskEdit{{Task: b.task("Call mum"), Complete: true}}
+				b.EditTasks(&edits)
+			},
+			want: nil,
+			check: func(t *testing.T, a, b *memoryTaskManager) {
+				if a.task("Call mum") == nil || len(b.tasks) != 0 {
+					t.Error("the task was copied again or closed in a")
+				}
+			},
+		},
+		{
+			name:   "two-way keeps a task closed on one side but changed on the other",
+			twoWay: true,
+			setup:  func(a, b *memoryTaskManager) { a.add("Call mum", "") },
+			change: func(a, b *memoryTaskManager) {
+				edits := []adapters.TaskEdit{{Task: a.task("Call mum"), Complete: true}}
+				a.EditTasks(&edits)
+				task := b.task("Call mum")
+				task.Tags = []string{"phone"}
+				b.touch(task, time.Hour)
+			},
+			want: []string{"unlink a>b Call mum (conflict)", "create b>a Call mum"},
+			check: func(t *testing.T, a, b *memoryTaskManager) {
+				if len(b.closed) != 0 || a.task("Call mum") == nil {
+					t.Errorf("got %v closed in b and %+v in a, want the task kept on both sides", b.closed, a.task("Call mum"))
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			a, b := newMemoryTaskManager("a", start), newMemoryTaskManager("b", start)
+			tt.setup(a, b)
+			changes := syncBoth(t, a, b, tt.twoWay)
+			if tt.change != nil {
+				tt.change(a, b)
+				changes = syncBoth(t, a, b, tt.twoWay)
+			}
+			if got := changeSummary(changes); !slices.Equal(got, tt.want) {
+				t.Errorf("got changes %q, want %q", got, tt.want)
+			}
+			if tt.check != nil {
+				tt.check(t, a, b)
+			}
+			if again := changeSummary(syncBoth(t, a, b, tt.twoWay)); len(again) > 0 {
+				t.Errorf("got changes %q on the next sync, want none", again)
+			}
+		})
+	}
+}
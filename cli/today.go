@@ -0,0 +1,269 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"github.com/dormunis/gitd/archivers/archiver"
+	"github.com/dormunis/gitd/taskmanagers/taskmanager"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type planModel struct {
+	tasks    *[]adapters.Task
+	reasons  []string
+	selected *[]bool
+	cursor   int
+	keys     planKeymap
+	help     help.Model
+	aborted  bool
+}
+
+type planKeymap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Toggle key.Binding
+	Save   key.Binding
+	Quit   key.Binding
+	Help   key.Binding
+}
+
+func (k planKeymap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Help, k.Save, k.Quit}
+}
+
+func (k planKeymap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down},
+		{k.Toggle},
+		{k.Help, k.Save, k.Quit},
+	}
+}
+
+var planKeys = planKeymap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "move up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "move down"),
+	),
+	Toggle: key.NewBinding(
+		key.WithKeys(" ", "enter"),
+		key.WithHelp("space/enter", "add to or remove from today's plan"),
+	),
+	Save: key.NewBinding(
+		key.WithKeys("esc", "q"),
+		key.WithHelp("esc/q", "save and quit"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys("ctrl+c"),
+		key.WithHelp("ctrl+c", "quit without saving"),
+	),
+	Help: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "toggle help"),
+	),
+}
+
+// Today writes the daily note: tasks due today or overdue, next actions and
+// yesterday's completions. With plan, the user first picks the tasks they
+// commit to for the day.
+func Today(taskManager adapters.TaskManagerAdapter, archiverName string, plan bool) error {
+	if archiverName == "" {
+		var err error
+		archiverName, err = defaultArchiver()
+		if err != nil {
+			return err
+		}
+	}
+	archiverAdapter, err := archiver.Initialize(archiverName, settings)
+	if err != nil {
+		return err
+	}
+
+	now, err := userNow(taskManager)
+	if err != nil {
+		return err
+	}
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	endOfDay := startOfDay.AddDate(0, 0, 1)
+
+	tasks, err := taskManager.FetchTasks()
+	if err != nil {
+		return err
+	}
+	completed, err := taskManager.FetchCompletedTasks(startOfDay.AddDate(0, 0, -1), startOfDay)
+	if err != nil {
+		return err
+	}
+
+	note := adapters.DailyNote{Date: startOfDay, Completed: completed}
+	for _, task := range tasks {
+		switch {
+		case task.DueDate != nil && task.DueDate.Before(startOfDay):
+			note.Overdue = append(note.Overdue, task)
+		case task.DueDate != nil && task.DueDate.Before(endOfDay):
+			note.Due = append(note.Due, task)
+		case task.Status == adapters.StatusNext && !isArticle(&task):
+			note.Next = append(note.Next, task)
+		}
+	}
+	for _, list := range []*[]adapters.Task{&note.Overdue, &note.Due, &note.Next} {
+		if err := taskmanager.SortTasks(list, []string{"due", "priority"}); err != nil {
+			return err
+		}
+	}
+
+	if plan {
+		if note.Planned, err = pickPlan(&note); err != nil {
+			return err
+		}
+	}
+
+	if err := archiverAdapter.WriteDailyNote(&note); err != nil {
+		return err
+	}
+	fmt.Printf("Daily note for %s written to %s\n", startOfDay.Format("2006-01-02"), archiverName)
+	return nil
+}
+
+// defaultArchiver is the only configured archiver, if there is exactly one.
+func defaultArchiver() (string, error) {
+	var names []string
+	for name := range settings.Archivers {
+		names = append(names, name)
+	}
+	switch len(names) {
+	case 0:
+		return "", adapters.Errorf(adapters.ErrorConfig, "no archiver configured, add one under archivers in the config file")
+	case 1:
+		return names[0], nil
+	}
+	slices.Sort(names)
+	return "", adapters.Errorf(adapters.ErrorValidation, "several archivers configured, pick one with --archiver (%s)", strings.Join(names, ", "))
+}
+
+// pickPlan lets the user choose today's tasks among the overdue, due and
+// next ones. The planned tasks are left out of the other sections.
+func pickPlan(note *adapters.DailyNote) ([]adapters.Task, error) {
+	var candidates []adapters.Task
+	var reasons []string
+	for _, group := range []struct {
+		reason string
+		tasks  []adapters.Task
+	}{
+		{"overdue", note.Overdue},
+		{"due today", note.Due},
+		{"next", note.Next},
+	} {
+		for _, task := range group.tasks {
+			candidates = append(candidates, task)
+			reasons = append(reasons, group.reason)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	selected := make([]bool, len(candidates))
+	programModel := planModel{
+		tasks:    &candidates,
+		reasons:  reasons,
+		selected: &selected,
+		keys:     planKeys,
+		help:     help.New(),
+	}
+	p := tea.NewProgram(programModel, tea.WithAltScreen())
+	finalModel, err := p.Run()
+	if err != nil {
+		return nil, fmt.Errorf("could not run program: %w", err)
+	}
+	if finalModel.(planModel).aborted {
+		return nil, adapters.ErrAborted
+	}
+
+	var planned []adapters.Task
+	plannedIDs := make(map[string]bool)
+	for i, isSelected := range selected {
+		if isSelected {
+			planned = append(planned, candidates[i])
+			plannedIDs[candidates[i].ID] = true
+		}
+	}
+	isPlanned := func(task adapters.Task) bool { return plannedIDs[task.ID] }
+	note.Overdue = slices.DeleteFunc(note.Overdue, isPlanned)
+	note.Due = slices.DeleteFunc(note.Due, isPlanned)
+	note.Next = slices.DeleteFunc(note.Next, isPlanned)
+	return planned, nil
+}
+
+func (m planModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m planModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, m.keys.Up):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case key.Matches(keyMsg, m.keys.Down):
+		if m.cursor < len(*m.tasks)-1 {
+			m.cursor++
+		}
+	case key.Matches(keyMsg, m.keys.Toggle):
+		(*m.selected)[m.cursor] = !(*m.selected)[m.cursor]
+	case key.Matches(keyMsg, m.keys.Save):
+		return m, tea.Quit
+	case key.Matches(keyMsg, m.keys.Quit):
+		m.aborted = true
+		return m, tea.Quit
+	case key.Matches(keyMsg, m.keys.Help):
+		m.help.ShowAll = !m.help.ShowAll
+	}
+	return m, nil
+}
+
+func (m planModel) View() string {
+	columns := []table.Column{
+		{Title: "Plan", Width: 6},
+		{Title: "Task", Width: 55},
+		{Title: "Project", Width: 25},
+		{Title: "Due", Width: 12},
+		{Title: "Why", Width: 10},
+	}
+
+	var rows []table.Row
+	count := 0
+	for i, task := range *m.tasks {
+		mark := " "
+		if (*m.selected)[i] {
+			mark = "x"
+			count++
+		}
+		rows = append(rows, table.Row{
+			fmt.Sprintf("[%s]", mark),
+			task.Content,
+			task.Project,
+			formatOptionalDate(task.DueDate),
+			m.reasons[i],
+		})
+	}
+
+	t := newTable(columns, rows, m.cursor)
+	title := fmt.Sprintf("Plan your day (%d picked)", count)
+	return titleStyle.Render(title) + "\n" + m.help.View(m.keys) + "\n\n" + t.View()
+}
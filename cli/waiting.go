@@ -0,0 +1,267 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type waitingModel struct {
+	tasks     *[]adapters.Task
+	followUps *[]bool
+	now       time.Time
+	cursor    int
+	keys      waitingKeymap
+	help      help.Model
+	aborted   bool
+}
+
+type waitingKeymap struct {
+	Up       key.Binding
+	Down     key.Binding
+	FollowUp key.Binding
+	Reset    key.Binding
+	Save     key.Binding
+	Quit     key.Binding
+	Help     key.Binding
+}
+
+func (k waitingKeymap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Help, k.Save, k.Quit}
+}
+
+func (k waitingKeymap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down},
+		{k.FollowUp, k.Reset},
+		{k.Help, k.Save, k.Quit},
+	}
+}
+
+var waitingKeys = waitingKeymap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "move up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "move down"),
+	),
+	FollowUp: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "follow up"),
+	),
+	Reset: key.NewBinding(
+		key.WithKeys("backspace", "delete"),
+		key.WithHelp("backspace/delete", "remove selection"),
+	),
+	Save: key.NewBinding(
+		key.WithKeys("esc", "q"),
+		key.WithHelp("esc/q", "save and quit"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys("ctrl+c"),
+		key.WithHelp("ctrl+c", "quit without saving"),
+	),
+	Help: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "toggle help"),
+	),
+}
+
+// Waiting lists waiting-for items, the longest waiting first, and records a
+// follow-up on the ones the user picks. With JSON or YAML output the items
+// are only printed.
+func Waiting(taskManager adapters.TaskManagerAdapter, format outputFormat) error {
+	tasks, err := taskManager.FetchTasks()
+	if err != nil {
+		return err
+	}
+
+	waiting := []adapters.Task{}
+	for _, task := range tasks {
+		if task.Status == adapters.StatusWaiting {
+			waiting = append(waiting, task)
+		}
+	}
+	slices.SortStableFunc(waiting, func(a, b adapters.Task) int {
+		return waitingSince(&a).Compare(waitingSince(&b))
+	})
+	if format != outputTable {
+		return writeOutput(format, waiting, nil)
+	}
+	if len(waiting) == 0 {
+		fmt.Println("Not waiting for anything")
+		return nil
+	}
+
+	followUps := make([]bool, len(waiting))
+	programModel := waitingModel{
+		tasks:     &waiting,
+		followUps: &followUps,
+		now:       time.Now(),
+		keys:      waitingKeys,
+		help:      help.New(),
+	}
+	p := tea.NewProgram(programModel, tea.WithAltScreen())
+	finalModel, err := p.Run()
+	if err != nil {
+		return fmt.Errorf("could not run program: %w", err)
+	}
+	if finalModel.(waitingModel).aborted {
+		return adapters.ErrAborted
+	}
+
+	var edits []adapters.TaskEdit
+	for i, followUp := range followUps {
+		if followUp {
+			edits = append(edits, followUpEdit(&waiting[i], programModel.now))
+		}
+	}
+	if len(edits) == 0 {
+		return nil
+	}
+
+	fmt.Printf("You are about to record a follow-up on %d items\n", len(edits))
+	fmt.Printf("Are you sure you want to continue? (y/n): ")
+	var response string
+	fmt.Scanln(&response)
+	if strings.ToLower(response) != "y" {
+		return adapters.ErrAborted
+	}
+	return taskManager.EditTasks(&edits)
+}
+
+// Delegate marks a task as waiting for someone, starting today.
+func Delegate(taskManager adapters.TaskManagerAdapter, taskID string, person string) error {
+	tasks, err := taskManager.FetchTasks()
+	if err != nil {
+		return err
+	}
+	index := slices.IndexFunc(tasks, func(task adapters.Task) bool { return task.ID == taskID })
+	if index == -1 {
+		return adapters.Errorf(adapters.ErrorValidation, "task not found: %s", taskID)
+	}
+	task := &tasks[index]
+
+	today := time.Now()
+	status := adapters.StatusWaiting
+	description := adapters.FormatWaitingInfo(task.Description, &adapters.WaitingInfo{
+		DelegatedTo: person,
+		Since:       &today,
+	})
+	edits := []adapters.TaskEdit{{Task: task, Status: &status, Description: &description}}
+	if err := taskManager.EditTasks(&edits); err != nil {
+		return err
+	}
+	fmt.Printf("Waiting for %s on %q\n", person, task.Content)
+	return nil
+}
+
+// waitingSince is when the task was last handed over or followed up on,
+// falling back to its last update.
+func waitingSince(task *adapters.Task) time.Time {
+	if task.Waiting != nil && task.Waiting.LastContact() != nil {
+		return *task.Waiting.LastContact()
+	}
+	return task.UpdatedDate
+}
+
+func followUpEdit(task *adapters.Task, now time.Time) adapters.TaskEdit {
+	info := adapters.WaitingInfo{}
+	if task.Waiting != nil {
+		info = *task.Waiting
+	}
+	if info.Since == nil {
+		since := waitingSince(task)
+		info.Since = &since
+	}
+	info.FollowedUp = &now
+
+	note := "Followed up on " + now.Format("2006-01-02")
+	if info.DelegatedTo != "" {
+		note = fmt.Sprintf("Followed up with %s on %s", info.DelegatedTo, now.Format("2006-01-02"))
+	}
+	description := adapters.FormatWaitingInfo(task.Description, &info)
+	return adapters.TaskEdit{Task: task, Description: &description, Note: &note}
+}
+
+func (m waitingModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m waitingModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, m.keys.Up):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case key.Matches(keyMsg, m.keys.Down):
+		if m.cursor < len(*m.tasks)-1 {
+			m.cursor++
+		}
+	case key.Matches(keyMsg, m.keys.FollowUp):
+		(*m.followUps)[m.cursor] = true
+		if m.cursor < len(*m.tasks)-1 {
+			m.cursor++
+		}
+	case key.Matches(keyMsg, m.keys.Reset):
+		(*m.followUps)[m.cursor] = false
+	case key.Matches(keyMsg, m.keys.Save):
+		return m, tea.Quit
+	case key.Matches(keyMsg, m.keys.Quit):
+		m.aborted = true
+		return m, tea.Quit
+	case key.Matches(keyMsg, m.keys.Help):
+		m.help.ShowAll = !m.help.ShowAll
+	}
+	return m, nil
+}
+
+func (m waitingModel) View() string {
+	columns := []table.Column{
+		{Title: "Action", Width: 8},
+		{Title: "Task", Width: 55},
+		{Title: "Waiting For", Width: 20},
+		{Title: "Since", Width: 12},
+		{Title: "Followed Up", Width: 12},
+		{Title: "Days", Width: 6},
+	}
+
+	var rows []table.Row
+	for i := range *m.tasks {
+		task := &(*m.tasks)[i]
+		mark := " "
+		if (*m.followUps)[i] {
+			mark = "f"
+		}
+		info := adapters.WaitingInfo{}
+		if task.Waiting != nil {
+			info = *task.Waiting
+		}
+		days := int(m.now.Sub(waitingSince(task)).Hours() / 24)
+		rows = append(rows, table.Row{
+			fmt.Sprintf("[%s]", mark),
+			task.Content,
+			info.DelegatedTo,
+			formatOptionalDate(info.Since),
+			formatOptionalDate(info.FollowedUp),
+			fmt.Sprintf("%d", days),
+		})
+	}
+
+	t := newTable(columns, rows, m.cursor)
+	return titleStyle.Render("Waiting for") + "\n" + m.help.View(m.keys) + "\n\n" + t.View()
+}
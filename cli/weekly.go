@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"github.com/dormunis/gitd/adapters"
+)
+
+var (
+	nextStatuses    = []adapters.Status{adapters.StatusNext}
+	somedayStatuses = []adapters.Status{adapters.StatusSomeday}
+	waitingStatuses = []adapters.Status{adapters.StatusWaiting}
+	staleTimeSpan   = adapters.TimeSpan{Months: 1}
+)
+
+var weeklyReviewSteps = []reviewStep{
+	{
+		Name:  "inbox",
+		Title: "Empty your inbox",
+		Run:   Inbox,
+	},
+	{
+		Name:   "next",
+		Title:  "Review next actions",
+		Select: selectByFilter(adapters.FilterRequest{Statuses: &nextStatuses}),
+	},
+	{
+		Name:   "waiting",
+		Title:  "Review waiting-for items",
+		Select: selectByFilter(adapters.FilterRequest{Statuses: &waitingStatuses}),
+	},
+	{
+		Name:   "someday",
+		Title:  "Review someday/maybe items",
+		Select: selectByFilter(adapters.FilterRequest{Statuses: &somedayStatuses}),
+	},
+	{
+		Name:  "projects",
+		Title: "Review projects without a next action",
+		Run: func(taskManager adapters.TaskManagerAdapter) error {
+			return ReviewProjects(taskManager, adapters.TimeSpan{}, outputTable)
+		},
+	},
+	{
+		Name:   "purge",
+		Title:  "Purge stale items",
+		Select: selectByFilter(adapters.FilterRequest{UpdatedBefore: &staleTimeSpan}),
+	},
+}
+
+func WeeklyReview(taskManager adapters.TaskManagerAdapter, restart bool) error {
+	return runReviewSteps(taskManager, "weekly", "weekly-review.json", weeklyReviewSteps, restart)
+}
+
+func selectInbox(tasks *[]adapters.Task) ([]adapters.Task, error) {
+	var inbox []adapters.Task
+	for _, task := range *tasks {
+		if task.Inbox {
+			inbox = append(inbox, task)
+		}
+	}
+	return inbox, nil
+}
@@ -0,0 +1,57 @@
+package formats
+
+import (
+	"encoding/csv"
+	"github.com/dormunis/gitd/adapters"
+	"io"
+	"strings"
+	"time"
+)
+
+var csvHeader = []string{
+	"id", "content", "description", "project", "section", "tags", "status",
+	"priority", "due", "created", "updated", "completed", "notes",
+}
+
+// writeCSV writes a row per task. Tags are separated by commas and notes by
+// newlines within their cell.
+func writeCSV(w io.Writer, tasks *[]adapters.Task) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, task := range *tasks {
+		due := ""
+		if task.DueDate != nil {
+			due = formatDue(*task.DueDate)
+		}
+		completed := ""
+		if task.CompletedAt != nil {
+			completed = task.CompletedAt.Format(time.RFC3339)
+		}
+		priority := ""
+		if task.Priority != 0 {
+			priority = task.Priority.String()
+		}
+		record := []string{
+			task.ID,
+			task.Content,
+			task.Description,
+			task.Project,
+			task.Section,
+			strings.Join(task.Tags, ","),
+			task.Status.String(),
+			priority,
+			due,
+			formatTime(task.CreatedDate),
+			formatTime(task.UpdatedDate),
+			completed,
+			strings.Join(task.Notes, "\n"),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
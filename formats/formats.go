@@ -0,0 +1,73 @@
+package formats
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"io"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatJSON    Format = "json"
+	FormatICS     Format = "ics"
+	FormatTodoTxt Format = "todotxt"
+)
+
+var exportFormats = []Format{FormatCSV, FormatJSON, FormatICS, FormatTodoTxt}
+
+func ParseFormat(input string) (Format, error) {
+	for _, format := range exportFormats {
+		if strings.EqualFold(input, string(format)) {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown format %s, expected csv, json, ics or todotxt", input)
+}
+
+// Export writes tasks to w in the given format.
+func Export(w io.Writer, format Format, tasks *[]adapters.Task) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, tasks)
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if *tasks == nil {
+			return encoder.Encode([]adapters.Task{})
+		}
+		return encoder.Encode(tasks)
+	case FormatICS:
+		return writeICS(w, tasks, time.Now())
+	case FormatTodoTxt:
+		return writeTodoTxt(w, tasks)
+	default:
+		return fmt.Errorf("unknown format %s", format)
+	}
+}
+
+// formatDue leaves out the time of day of due dates without one.
+func formatDue(due time.Time) string {
+	if isAllDay(due) {
+		return due.Format("2006-01-02")
+	}
+	return due.Format(time.RFC3339)
+}
+
+// formatTime leaves unknown times empty, such as the creation date of tasks
+// fetched from an archive of completed ones.
+func formatTime(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+	return date.Format(time.RFC3339)
+}
+
+func isAllDay(date time.Time) bool {
+	hour, minute, second := date.Clock()
+	return hour == 0 && minute == 0 && second == 0
+}
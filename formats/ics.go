@@ -0,0 +1,98 @@
+package formats
+
+import (
+	"bufio"
+	"github.com/dormunis/gitd/adapters"
+	"io"
+	"strings"
+	"time"
+)
+
+const icsTimeFormat = "20060102T150405Z"
+
+// icsPriorities map to RFC 5545 priorities, 1 being the highest. Low is the
+// task managers' default and is left undefined.
+var icsPriorities = map[adapters.Priority]string{
+	adapters.PriorityCritical: "1",
+	adapters.PriorityHigh:     "3",
+	adapters.PriorityMedium:   "5",
+}
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// writeICS writes a calendar with a VTODO per task. The project, section and
+// tags become categories; the notes are appended to the description.
+func writeICS(w io.Writer, tasks *[]adapters.Task, now time.Time) error {
+	writer := bufio.NewWriter(w)
+	writeICSLine(writer, "BEGIN:VCALENDAR")
+	writeICSLine(writer, "VERSION:2.0")
+	writeICSLine(writer, "PRODID:-//gitd//gitd//EN")
+	for _, task := range *tasks {
+		writeICSLine(writer, "BEGIN:VTODO")
+		writeICSLine(writer, "UID:"+task.ID+"@"+task.TaskManger+".gitd")
+		writeICSLine(writer, "DTSTAMP:"+now.UTC().Format(icsTimeFormat))
+		if !task.CreatedDate.IsZero() {
+			writeICSLine(writer, "CREATED:"+task.CreatedDate.UTC().Format(icsTimeFormat))
+		}
+		if !task.UpdatedDate.IsZero() {
+			writeICSLine(writer, "LAST-MODIFIED:"+task.UpdatedDate.UTC().Format(icsTimeFormat))
+		}
+		writeICSLine(writer, "SUMMARY:"+icsEscaper.Replace(task.Content))
+
+		description := strings.Join(append([]string{task.Description}, task.Notes...), "\n\n")
+		if description = strings.TrimSpace(description); description != "" {
+			writeICSLine(writer, "DESCRIPTION:"+icsEscaper.Replace(description))
+		}
+		if task.DueDate != nil {
+			if isAllDay(*task.DueDate) {
+				writeICSLine(writer, "DUE;VALUE=DATE:"+task.DueDate.Format("20060102"))
+			} else {
+				writeICSLine(writer, "DUE:"+task.DueDate.UTC().Format(icsTimeFormat))
+			}
+		}
+
+		var categories []string
+		for _, category := range append([]string{task.Project, task.Section}, task.Tags...) {
+			if category != "" {
+				categories = append(categories, icsEscaper.Replace(category))
+			}
+		}
+		if len(categories) > 0 {
+			writeICSLine(writer, "CATEGORIES:"+strings.Join(categories, ","))
+		}
+		if priority, ok := icsPriorities[task.Priority]; ok {
+			writeICSLine(writer, "PRIORITY:"+priority)
+		}
+		if task.Status == adapters.StatusCompleted {
+			writeICSLine(writer, "STATUS:COMPLETED")
+			if task.CompletedAt != nil {
+				writeICSLine(writer, "COMPLETED:"+task.CompletedAt.UTC().Format(icsTimeFormat))
+			}
+		} else {
+			writeICSLine(writer, "STATUS:NEEDS-ACTION")
+		}
+		writeICSLine(writer, "END:VTODO")
+	}
+	writeICSLine(writer, "END:VCALENDAR")
+	return writer.Flush()
+}
+
+// writeICSLine folds lines longer than 75 octets, without splitting a UTF-8
+// character, and ends them with CRLF as RFC 5545 requires.
+func writeICSLine(writer *bufio.Writer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		writer.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = 74 // the leading space counts
+	}
+	writer.WriteString(line + "\r\n")
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
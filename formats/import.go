@@ -0,0 +1,358 @@
+package formats
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record is a task read from a file, before it is matched against a task
+// manager.
+type Record struct {
+	Line        int // line, or entry for JSON, for error messages
+	Content     string
+	Description string
+	Project     string
+	Tags        []string
+	Priority    adapters.Priority
+	Due         string // as understood by the task manager, e.g. 2026-10-20
+	Notes       []string
+	Completed   bool
+}
+
+// importFields are the fields a column can be mapped to, with the column
+// names tried, regardless of case, when there is no mapping for the field.
+var importFields = map[string][]string{
+	"content":     {"content", "title", "task", "name", "summary"},
+	"description": {"description", "desc"},
+	"project":     {"project", "list"},
+	"tags":        {"tags", "labels", "categories"},
+	"priority":    {"priority"},
+	"due":         {"due", "due_at", "due date", "due_date"},
+	"notes":       {"notes", "comments"},
+	"status":      {"status"},
+	"completed":   {"completed", "completed_at", "done"},
+}
+
+// Import reads the tasks of a file. mapping names the column, or JSON key,
+// holding a field, e.g. {"content": "Title"}, and applies to CSV and JSON.
+func Import(r io.Reader, format Format, mapping map[string]string) ([]Record, error) {
+	for field := range mapping {
+		if _, ok := importFields[field]; !ok {
+			fields := make([]string, 0, len(importFields))
+			for name := range importFields {
+				fields = append(fields, name)
+			}
+			slices.Sort(fields)
+			return nil, fmt.Errorf("unknown field %s in mapping, expected one of %s", field, strings.Join(fields, ", "))
+		}
+	}
+
+	switch format {
+	case FormatCSV:
+		return readCSV(r, mapping)
+	case FormatJSON:
+		return readJSON(r, mapping)
+	case FormatTodoTxt:
+		if len(mapping) > 0 {
+			return nil, fmt.Errorf("todo.txt has no columns to map")
+		}
+		return readTodoTxt(r)
+	default:
+		return nil, fmt.Errorf("cannot import %s", format)
+	}
+}
+
+func readCSV(r io.Reader, mapping map[string]string) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	header := make([]string, len(rows[0]))
+	for i, name := range rows[0] {
+		header[i] = strings.ToLower(strings.TrimSpace(name))
+	}
+	columns, err := mapColumns(header, mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	for i, row := range rows[1:] {
+		values := make(map[string]any)
+		for j, value := range row {
+			if j < len(header) {
+				values[header[j]] = value
+			}
+		}
+		record, err := newRecord(i+2, values, columns)
+		if err != nil {
+			return nil, err
+		}
+		if record != nil {
+			records = append(records, *record)
+		}
+	}
+	return records, nil
+}
+
+func readJSON(r io.Reader, mapping map[string]string) ([]Record, error) {
+	var entries []map[string]any
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("expected an array of objects: %w", err)
+	}
+
+	var header []string
+	lowered := make([]map[string]any, len(entries))
+	for i, entry := range entries {
+		lowered[i] = make(map[string]any)
+		for key, value := range entry {
+			key = strings.ToLower(key)
+			lowered[i][key] = value
+			if !slices.Contains(header, key) {
+				header = append(header, key)
+			}
+		}
+	}
+	columns, err := mapColumns(header, mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	for i, values := range lowered {
+		record, err := newRecord(i+1, values, columns)
+		if err != nil {
+			return nil, err
+		}
+		if record != nil {
+			records = append(records, *record)
+		}
+	}
+	return records, nil
+}
+
+// mapColumns picks the column of every field found in the header.
+func mapColumns(header []string, mapping map[string]string) (map[string]string, error) {
+	columns := make(map[string]string)
+	for field, names := range importFields {
+		if column, ok := mapping[field]; ok {
+			column = strings.ToLower(column)
+			if !slices.Contains(header, column) {
+				return nil, fmt.Errorf("column %s mapped to %s is missing", mapping[field], field)
+			}
+			columns[field] = column
+			continue
+		}
+		for _, name := range names {
+			if slices.Contains(header, name) {
+				columns[field] = name
+				break
+			}
+		}
+	}
+	if _, ok := columns["content"]; !ok {
+		return nil, fmt.Errorf("no content column, map one with content=<column>")
+	}
+	return columns, nil
+}
+
+// newRecord reads a row keyed by column. Rows without any value are skipped.
+func newRecord(line int, values map[string]any, columns map[string]string) (*Record, error) {
+	text := func(field string) string {
+		column, ok := columns[field]
+		if !ok {
+			return ""
+		}
+		return strings.TrimSpace(strings.Join(valueStrings(values[column]), ", "))
+	}
+	list := func(field string, separator string) []string {
+		column, ok := columns[field]
+		if !ok {
+			return nil
+		}
+		var items []string
+		for _, value := range valueStrings(values[column]) {
+			for _, item := range strings.Split(value, separator) {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+		}
+		return items
+	}
+
+	empty := true
+	for _, value := range values {
+		if strings.TrimSpace(strings.Join(valueStrings(value), "")) != "" {
+			empty = false
+		}
+	}
+	if empty {
+		return nil, nil
+	}
+
+	record := &Record{
+		Line:        line,
+		Content:     strings.Join(strings.Fields(text("content")), " "),
+		Description: text("description"),
+		Project:     text("project"),
+		Notes:       list("notes", "\n"),
+	}
+	if record.Content == "" {
+		return nil, fmt.Errorf("line %d: missing content", line)
+	}
+	for _, tag := range list("tags", ",") {
+		record.Tags = append(record.Tags, strings.TrimLeft(tag, "@#"))
+	}
+
+	var err error
+	if record.Priority, err = parseImportPriority(text("priority")); err != nil {
+		return nil, fmt.Errorf("line %d: %w", line, err)
+	}
+	record.Due = parseImportDue(text("due"))
+	record.Completed = isCompletedStatus(text("status")) || isSet(text("completed"))
+	return record, nil
+}
+
+func valueStrings(value any) []string {
+	switch value := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{value}
+	case float64:
+		return []string{strconv.FormatFloat(value, 'f', -1, 64)}
+	case bool:
+		return []string{strconv.FormatBool(value)}
+	case []any:
+		var values []string
+		for _, item := range value {
+			values = append(values, valueStrings(item)...)
+		}
+		return values
+	default:
+		data, _ := json.Marshal(value)
+		return []string{string(data)}
+	}
+}
+
+// parseImportPriority also accepts the p1 to p4 notation, p1 being the most
+// urgent.
+func parseImportPriority(value string) (adapters.Priority, error) {
+	if value == "" {
+		return 0, nil
+	}
+	if trimmed := strings.TrimLeft(value, "pP"); trimmed != value {
+		if _, err := strconv.Atoi(trimmed); err == nil {
+			value = trimmed
+		}
+	}
+	return adapters.ParsePriority(value)
+}
+
+// parseImportDue turns timestamps into dates, with the time of day when there
+// is one, and leaves anything else for the task manager to interpret.
+func parseImportDue(value string) string {
+	due, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	if isAllDay(due) {
+		return due.Format("2006-01-02")
+	}
+	return due.Local().Format("2006-01-02 15:04")
+}
+
+func isCompletedStatus(value string) bool {
+	if number, err := strconv.Atoi(value); err == nil {
+		return adapters.Status(number) == adapters.StatusCompleted
+	}
+	status, err := adapters.ParseStatus(value)
+	return (err == nil && status == adapters.StatusCompleted) || strings.EqualFold(value, "done")
+}
+
+func isSet(value string) bool {
+	switch strings.ToLower(value) {
+	case "", "false", "0", "no":
+		return false
+	}
+	return true
+}
+
+// readTodoTxt reads the format written by writeTodoTxt. Underscores in project
+// names are read back as spaces.
+func readTodoTxt(r io.Reader) ([]Record, error) {
+	var records []Record
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		words := strings.Fields(scanner.Text())
+		if len(words) == 0 {
+			continue
+		}
+
+		record := Record{Line: line}
+		if words[0] == "x" {
+			record.Completed = true
+			words = words[1:]
+			if len(words) > 0 && isTodoTxtDate(words[0]) {
+				words = words[1:]
+			}
+		} else if len(words[0]) == 3 && words[0][0] == '(' && words[0][2] == ')' {
+			record.Priority = todoTxtPriority(words[0][1])
+			words = words[1:]
+		}
+		if len(words) > 0 && isTodoTxtDate(words[0]) {
+			words = words[1:] // creation date
+		}
+
+		var content []string
+		for _, word := range words {
+			switch {
+			case len(word) > 1 && word[0] == '+' && record.Project == "":
+				record.Project = strings.ReplaceAll(word[1:], "_", " ")
+			case len(word) > 1 && word[0] == '@':
+				record.Tags = append(record.Tags, word[1:])
+			case strings.HasPrefix(word, "due:") && len(word) > 4:
+				record.Due = word[4:]
+			case strings.HasPrefix(word, "pri:") && len(word) == 5:
+				record.Priority = todoTxtPriority(word[4])
+			default:
+				content = append(content, word)
+			}
+		}
+		record.Content = strings.Join(content, " ")
+		if record.Content == "" {
+			return nil, fmt.Errorf("line %d: missing content", line)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+func isTodoTxtDate(word string) bool {
+	_, err := time.Parse("2006-01-02", word)
+	return err == nil
+}
+
+func todoTxtPriority(letter byte) adapters.Priority {
+	for priority, name := range todoTxtPriorities {
+		if name[0] == letter {
+			return priority
+		}
+	}
+	return adapters.PriorityLow
+}
@@ -0,0 +1,64 @@
+package formats
+
+import (
+	"bufio"
+	"github.com/dormunis/gitd/adapters"
+	"io"
+	"strings"
+)
+
+var todoTxtPriorities = map[adapters.Priority]string{
+	adapters.PriorityCritical: "A",
+	adapters.PriorityHigh:     "B",
+	adapters.PriorityMedium:   "C",
+}
+
+// writeTodoTxt writes a line per task in the todo.txt format, e.g.
+//
+//	x 2026-10-02 2026-09-14 (B) Call dentist +Personal @phone due:2026-10-01
+//
+// Spaces in projects and tags become underscores, and descriptions and notes
+// are left out as the format has no room for them.
+func writeTodoTxt(w io.Writer, tasks *[]adapters.Task) error {
+	writer := bufio.NewWriter(w)
+	for _, task := range *tasks {
+		var parts []string
+		if task.Status == adapters.StatusCompleted {
+			parts = append(parts, "x")
+			if task.CompletedAt != nil {
+				parts = append(parts, task.CompletedAt.Format("2006-01-02"))
+			}
+		} else if priority, ok := todoTxtPriorities[task.Priority]; ok {
+			parts = append(parts, "("+priority+")")
+		}
+		// The creation date can only follow a completion date, when there is one.
+		if !task.CreatedDate.IsZero() && (task.Status != adapters.StatusCompleted || task.CompletedAt != nil) {
+			parts = append(parts, task.CreatedDate.Format("2006-01-02"))
+		}
+
+		parts = append(parts, strings.Join(strings.Fields(task.Content), " "))
+		if task.Project != "" && !task.Inbox {
+			parts = append(parts, "+"+todoTxtWord(task.Project))
+		}
+		for _, tag := range task.Tags {
+			parts = append(parts, "@"+todoTxtWord(tag))
+		}
+		if task.DueDate != nil {
+			parts = append(parts, "due:"+task.DueDate.Format("2006-01-02"))
+		}
+		if task.Status == adapters.StatusCompleted {
+			if priority, ok := todoTxtPriorities[task.Priority]; ok {
+				parts = append(parts, "pri:"+priority)
+			}
+		}
+
+		if _, err := writer.WriteString(strings.Join(parts, " ") + "\n"); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+func todoTxtWord(value string) string {
+	return strings.Join(strings.Fields(value), "_")
+}
@@ -0,0 +1,253 @@
+package query
+
+import (
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Expr is a parsed filter expression.
+type Expr interface {
+	adapters.TaskMatcher
+}
+
+type operator string
+
+const (
+	opMatch        operator = ":"
+	opEqual        operator = "="
+	opNotEqual     operator = "!="
+	opLess         operator = "<"
+	opLessEqual    operator = "<="
+	opGreater      operator = ">"
+	opGreaterEqual operator = ">="
+)
+
+type andExpr struct{ left, right Expr }
+type orExpr struct{ left, right Expr }
+type notExpr struct{ inner Expr }
+
+type comparison struct {
+	match func(task *adapters.Task, now time.Time) bool
+}
+
+func (e *andExpr) Matches(task *adapters.Task, now time.Time) bool {
+	return e.left.Matches(task, now) && e.right.Matches(task, now)
+}
+
+func (e *orExpr) Matches(task *adapters.Task, now time.Time) bool {
+	return e.left.Matches(task, now) || e.right.Matches(task, now)
+}
+
+func (e *notExpr) Matches(task *adapters.Task, now time.Time) bool {
+	return !e.inner.Matches(task, now)
+}
+
+func (c *comparison) Matches(task *adapters.Task, now time.Time) bool {
+	return c.match(task, now)
+}
+
+type field struct {
+	operators []operator
+	compile   func(op operator, value string) (Expr, error)
+}
+
+func (f field) supports(op operator) bool {
+	return slices.Contains(f.operators, op)
+}
+
+var (
+	equalityOperators = []operator{opMatch, opEqual, opNotEqual}
+	orderedOperators  = []operator{opMatch, opEqual, opNotEqual, opLess, opLessEqual, opGreater, opGreaterEqual}
+	dateOperators     = []operator{opMatch, opEqual, opLess, opLessEqual, opGreater, opGreaterEqual}
+)
+
+var fields = map[string]field{
+	"project":  {operators: equalityOperators, compile: compileProject},
+	"tag":      {operators: equalityOperators, compile: compileTag},
+	"status":   {operators: equalityOperators, compile: compileStatus},
+	"priority": {operators: orderedOperators, compile: compilePriority},
+	"content":  {operators: equalityOperators, compile: compileContent},
+	"id":       {operators: equalityOperators, compile: compileID},
+	"created":  {operators: dateOperators, compile: compileDate(func(t *adapters.Task) time.Time { return t.CreatedDate })},
+	"updated":  {operators: dateOperators, compile: compileDate(func(t *adapters.Task) time.Time { return t.UpdatedDate })},
+}
+
+func fieldNames() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func negateIf(op operator, match func(task *adapters.Task, now time.Time) bool) Expr {
+	if op == opNotEqual {
+		return &comparison{match: func(task *adapters.Task, now time.Time) bool { return !match(task, now) }}
+	}
+	return &comparison{match: match}
+}
+
+// compileProject treats ':' as a glob match (as --project does) and '=' as an
+// exact match.
+func compileProject(op operator, value string) (Expr, error) {
+	if op == opMatch {
+		if _, err := path.Match(value, ""); err != nil {
+			return nil, err
+		}
+		return &comparison{match: func(task *adapters.Task, _ time.Time) bool {
+			matched, _ := path.Match(value, task.Project)
+			return matched
+		}}, nil
+	}
+	return negateIf(op, func(task *adapters.Task, _ time.Time) bool {
+		return task.Project == value
+	}), nil
+}
+
+func compileTag(op operator, value string) (Expr, error) {
+	return negateIf(op, func(task *adapters.Task, _ time.Time) bool {
+		return slices.Contains(task.Tags, value)
+	}), nil
+}
+
+func compileStatus(op operator, value string) (Expr, error) {
+	status, err := adapters.ParseStatus(value)
+	if err != nil {
+		return nil, err
+	}
+	return negateIf(op, func(task *adapters.Task, _ time.Time) bool {
+		return task.Status == status
+	}), nil
+}
+
+func compileContent(op operator, value string) (Expr, error) {
+	if op == opMatch {
+		lowered := strings.ToLower(value)
+		return &comparison{match: func(task *adapters.Task, _ time.Time) bool {
+			return strings.Contains(strings.ToLower(task.Content), lowered)
+		}}, nil
+	}
+	return negateIf(op, func(task *adapters.Task, _ time.Time) bool {
+		return task.Content == value
+	}), nil
+}
+
+func compileID(op operator, value string) (Expr, error) {
+	return negateIf(op, func(task *adapters.Task, _ time.Time) bool {
+		return task.ID == value
+	}), nil
+}
+
+// compilePriority compares priority values, where 1 is critical and 4 low,
+// so priority<=2 selects critical and high priority tasks.
+func compilePriority(op operator, value string) (Expr, error) {
+	priority, err := adapters.ParsePriority(value)
+	if err != nil {
+		return nil, err
+	}
+	return &comparison{match: func(task *adapters.Task, _ time.Time) bool {
+		return compareInts(int(task.Priority), op, int(priority))
+	}}, nil
+}
+
+func compareInts(a int, op operator, b int) bool {
+	switch op {
+	case opLess:
+		return a < b
+	case opLessEqual:
+		return a <= b
+	case opGreater:
+		return a > b
+	case opGreaterEqual:
+		return a >= b
+	case opNotEqual:
+		return a != b
+	default:
+		return a == b
+	}
+}
+
+// compileDate accepts either a relative age (e.g. "30d" or "2 weeks"), which
+// compares how long ago the date was, or an absolute date (YYYY-MM-DD).
+// Thus updated<30d selects tasks updated less than 30 days ago, while
+// updated<2026-01-01 selects tasks last updated before that day. An age
+// compared with '=' matches the whole day, and days start at midnight in the
+// timezone of now.
+func compileDate(get func(*adapters.Task) time.Time) func(operator, string) (Expr, error) {
+	return func(op operator, value string) (Expr, error) {
+		if span, err := adapters.NewTimeSpan(value); err == nil {
+			return &comparison{match: func(task *adapters.Task, now time.Time) bool {
+				threshold := span.ModifyDate(now, false)
+				return compareAge(get(task), op, threshold)
+			}}, nil
+		}
+
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return nil, fmt.Errorf("expected a timespan such as 30d or a date such as 2006-01-02")
+		}
+		return &comparison{match: func(task *adapters.Task, now time.Time) bool {
+			day := time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, now.Location())
+			return compareDay(get(task), op, day)
+		}}, nil
+	}
+}
+
+// compareDay compares date against the whole day starting at day.
+func compareDay(date time.Time, op operator, day time.Time) bool {
+	nextDay := day.AddDate(0, 0, 1)
+	switch op {
+	case opLess:
+		return date.Before(day)
+	case opLessEqual:
+		return date.Before(nextDay)
+	case opGreater:
+		return !date.Before(nextDay)
+	case opGreaterEqual:
+		return !date.Before(day)
+	default:
+		return !date.Before(day) && date.Before(nextDay)
+	}
+}
+
+// compareAge compares the age of date against the age of threshold: a date
+// is "younger" than the threshold when it comes after it. Equality holds for
+// dates on the same day as the threshold.
+func compareAge(date time.Time, op operator, threshold time.Time) bool {
+	switch op {
+	case opLess, opMatch:
+		return date.After(threshold)
+	case opLessEqual:
+		return !date.Before(threshold)
+	case opGreater:
+		return date.Before(threshold)
+	case opGreaterEqual:
+		return !date.After(threshold)
+	default:
+		year, month, day := threshold.Date()
+		return compareDay(date, opEqual, time.Date(year, month, day, 0, 0, 0, 0, threshold.Location()))
+	}
+}
+
+// And combines expressions so that all of them must match. Nil expressions
+// are skipped.
+func And(exprs ...adapters.TaskMatcher) Expr {
+	var combined Expr
+	for _, expr := range exprs {
+		if expr == nil {
+			continue
+		}
+		if combined == nil {
+			combined = expr
+		} else {
+			combined = &andExpr{left: combined, right: expr}
+		}
+	}
+	return combined
+}
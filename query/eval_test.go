@@ -0,0 +1,105 @@
+package query
+
+import (
+	"github.com/dormunis/gitd/adapters"
+	"testing"
+	"time"
+)
+
+func TestMatches(t *testing.T) {
+	tokyo := time.FixedZone("Tokyo", 9*60*60)
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, tokyo)
+	task := adapters.Task{
+		ID:          "42",
+		Content:     "Renew Passport",
+		Project:     "Home/Admin",
+		Status:      adapters.StatusNext,
+		Priority:    adapters.PriorityHigh,
+		Tags:        []string{"errand", "phone"},
+		CreatedDate: time.Date(2026, 1, 1, 0, 30, 0, 0, tokyo),
+		UpdatedDate: now.AddDate(0, 0, -7).Add(-3 * time.Hour),
+	}
+
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"project:Home/*", true},
+		{"project:Home", false},
+		{"project=Home/Admin", true},
+		{"project!=Home/Admin", false},
+		{"tag:phone", true},
+		{"tag!=phone", false},
+		{"status:next", true},
+		{"status!=someday", true},
+		{"content:passport", true},
+		{"content=passport", false},
+		{`content="Renew Passport"`, true},
+		{"id=42", true},
+
+		{"priority=2", true},
+		{"priority=high", true},
+		{"priority<=2", true},
+		{"priority<=critical", false},
+		{"priority>=3", false},
+		{"priority<3", true},
+		{"priority!=low", true},
+
+		{"updated<30d", true},
+		{"updated:30d", true},
+		{"updated>1w", true},
+		{"updated>8d", false},
+		{"updated=7d", true},
+		{"updated=6d", false},
+		{"updated=8d", false},
+		{"updated>=1w", true},
+		{"updated<=1w", false},
+
+		{"created=2026-01-01", true},
+		{"created<2026-01-01", false},
+		{"created<=2026-01-01", true},
+		{"created>2025-12-31", true},
+		{"created>=2026-01-02", false},
+		{"updated=2026-03-08", true},
+
+		{"tag:phone and not tag:home", true},
+		{"tag:home or (status:next and priority<=2)", true},
+		{"not (tag:phone or tag:home)", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := Parse(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := expr.Matches(&task, now); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAbsoluteDatesFollowTheTimezoneOfNow(t *testing.T) {
+	expr, err := Parse("created=2026-01-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Half past midnight in Tokyo is still New Year's Eve in UTC.
+	task := adapters.Task{CreatedDate: time.Date(2025, 12, 31, 15, 30, 0, 0, time.UTC)}
+	if !expr.Matches(&task, time.Date(2026, 3, 15, 12, 0, 0, 0, time.FixedZone("Tokyo", 9*60*60))) {
+		t.Error("got no match in Tokyo")
+	}
+	if expr.Matches(&task, time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)) {
+		t.Error("got a match in UTC")
+	}
+}
+
+func TestAnd(t *testing.T) {
+	if And(nil, nil) != nil {
+		t.Error("got an expression from nil ones")
+	}
+	tag, _ := Parse("tag:a")
+	if And(nil, tag, nil) != tag {
+		t.Error("got a combination of a single expression")
+	}
+}
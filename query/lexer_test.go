@@ -0,0 +1,59 @@
+package query
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []token
+	}{
+		{"tag:next", []token{{tokenWord, "tag", 0}, {tokenOperator, ":", 3}, {tokenWord, "next", 4}, {tokenEOF, "", 8}}},
+		{"priority<=2", []token{{tokenWord, "priority", 0}, {tokenOperator, "<=", 8}, {tokenWord, "2", 10}, {tokenEOF, "", 11}}},
+		{"not (a!=b)", []token{{tokenWord, "not", 0}, {tokenLParen, "(", 4}, {tokenWord, "a", 5}, {tokenOperator, "!=", 6}, {tokenWord, "b", 8}, {tokenRParen, ")", 9}, {tokenEOF, "", 10}}},
+		{`project:"Home Admin"`, []token{{tokenWord, "project", 0}, {tokenOperator, ":", 7}, {tokenString, "Home Admin", 8}, {tokenEOF, "", 20}}},
+		{`content:'it\'s'`, []token{{tokenWord, "content", 0}, {tokenOperator, ":", 7}, {tokenString, "it's", 8}, {tokenEOF, "", 15}}},
+		{"updated>=2026-01-01", []token{{tokenWord, "updated", 0}, {tokenOperator, ">=", 7}, {tokenWord, "2026-01-01", 9}, {tokenEOF, "", 19}}},
+		{"  ", []token{{tokenEOF, "", 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := tokenize(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("token %d: got %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTokenizeErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		pos   int
+	}{
+		{`content:"open`, 8},
+		{"tag:next !", 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := tokenize(tt.input)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("got error %v, want a parse error", err)
+			}
+			if parseErr.Pos != tt.pos {
+				t.Errorf("got position %d, want %d", parseErr.Pos, tt.pos)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseError describes a malformed expression and where in the input it
+// went wrong.
+type ParseError struct {
+	Input string
+	Pos   int
+	Msg   string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s at position %d\n  %s\n  %s^", e.Msg, e.Pos+1, e.Input, strings.Repeat(" ", e.Pos))
+}
+
+type parser struct {
+	input  string
+	tokens []token
+	pos    int
+}
+
+// Parse compiles a filter expression such as
+//
+//	project:Work and (tag:waiting or priority<=2) and updated<30d and not status:someday
+//
+// into an Expr that can be evaluated against tasks.
+func Parse(input string) (Expr, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{input: input, tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, p.errorf(p.peek(), "empty filter expression")
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorf(tok, "unexpected %s, expected 'and', 'or' or end of input", tok)
+	}
+	return expr, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) errorf(tok token, format string, args ...any) error {
+	return &ParseError{Input: p.input, Pos: tok.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) isKeyword(keyword string) bool {
+	tok := p.peek()
+	return tok.kind == tokenWord && strings.EqualFold(tok.value, keyword)
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orExpr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &andExpr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (Expr, error) {
+	if p.isKeyword("not") {
+		p.next()
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notExpr{inner: inner}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenLParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, p.errorf(closing, "expected ')' to close '(' at position %d, got %s", tok.pos+1, closing)
+		}
+		return expr, nil
+	case tokenWord:
+		return p.parseComparison(tok)
+	default:
+		return nil, p.errorf(tok, "unexpected %s, expected a field comparison such as tag:next", tok)
+	}
+}
+
+func (p *parser) parseComparison(fieldTok token) (Expr, error) {
+	field, ok := fields[strings.ToLower(fieldTok.value)]
+	if !ok {
+		return nil, p.errorf(fieldTok, "unknown field %s, expected one of %s", fieldTok, strings.Join(fieldNames(), ", "))
+	}
+
+	opTok := p.next()
+	if opTok.kind != tokenOperator {
+		return nil, p.errorf(opTok, "expected an operator after %s, got %s", fieldTok, opTok)
+	}
+	op := operator(opTok.value)
+	if !field.supports(op) {
+		return nil, p.errorf(opTok, "operator '%s' is not supported for field %s", op, fieldTok)
+	}
+
+	valueTok := p.next()
+	if valueTok.kind != tokenWord && valueTok.kind != tokenString {
+		return nil, p.errorf(valueTok, "expected a value after %s%s, got %s", fieldTok.value, op, valueTok)
+	}
+	cmp, err := field.compile(op, valueTok.value)
+	if err != nil {
+		return nil, p.errorf(valueTok, "invalid value %s for field %s: %s", valueTok, fieldTok, err)
+	}
+	return cmp, nil
+}
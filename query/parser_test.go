@@ -0,0 +1,87 @@
+package query
+
+import (
+	"errors"
+	"github.com/dormunis/gitd/adapters"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		pos   int
+		msg   string
+	}{
+		{"", 0, "empty filter expression"},
+		{"colour:red", 0, "unknown field 'colour'"},
+		{"tag", 3, "expected an operator"},
+		{"tag:", 4, "expected a value"},
+		{"tag<next", 3, "operator '<' is not supported"},
+		{"created!=2026-01-01", 7, "operator '!=' is not supported"},
+		{"(tag:next", 9, "expected ')'"},
+		{"tag:next tag:phone", 9, "expected 'and', 'or' or end of input"},
+		{"and tag:next", 0, "unknown field 'and'"},
+		{"status:done", 7, "invalid value 'done'"},
+		{"priority:urgent", 9, "invalid value 'urgent'"},
+		{"updated<yesterday", 8, "invalid value 'yesterday'"},
+		{"project:[Home", 8, "invalid value '[Home'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("got error %v, want a parse error", err)
+			}
+			if parseErr.Pos != tt.pos || !strings.Contains(parseErr.Msg, tt.msg) {
+				t.Errorf("got %q at %d, want %q at %d", parseErr.Msg, parseErr.Pos, tt.msg, tt.pos)
+			}
+		})
+	}
+}
+
+func TestParsePrecedence(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"tag:a or tag:b and tag:c", "(a or (b and c))"},
+		{"(tag:a or tag:b) and tag:c", "((a or b) and c)"},
+		{"not tag:a and tag:b", "((not a) and b)"},
+		{"not not tag:a", "(not (not a))"},
+		{"tag:a AND tag:b Or tag:c", "((a and b) or c)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := Parse(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := describe(t, expr); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// describe prints the shape of expressions over single tags, found by
+// matching each tag on its own.
+func describe(t *testing.T, expr Expr) string {
+	switch e := expr.(type) {
+	case *andExpr:
+		return "(" + describe(t, e.left) + " and " + describe(t, e.right) + ")"
+	case *orExpr:
+		return "(" + describe(t, e.left) + " or " + describe(t, e.right) + ")"
+	case *notExpr:
+		return "(not " + describe(t, e.inner) + ")"
+	}
+	for _, tag := range []string{"a", "b", "c"} {
+		if expr.Matches(&adapters.Task{Tags: []string{tag}}, time.Now()) {
+			return tag
+		}
+	}
+	t.Fatalf("unexpected expression %#v", expr)
+	return ""
+}
@@ -0,0 +1,277 @@
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"hash/fnv"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// BM25 parameters: K1 dampens repeated terms, B normalises by length.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	prefixWeight = 0.7
+	fuzzyWeight  = 0.5
+)
+
+// Document is an indexed task, current or archived.
+type Document struct {
+	ID          string         `json:"id"`
+	Content     string         `json:"content"`
+	Project     string         `json:"project"`
+	Archived    bool           `json:"archived,omitempty"`
+	Date        time.Time      `json:"date"` // created, or completed once archived
+	Fingerprint uint64         `json:"fingerprint"`
+	Terms       map[string]int `json:"terms"`
+	Length      int            `json:"length"`
+}
+
+type Result struct {
+	Document *Document
+	Score    float64
+}
+
+// Index is an inverted index over tasks, ranked with BM25. It is kept on
+// disk and updated incrementally: unchanged tasks are not re-indexed.
+type Index struct {
+	Documents map[string]*Document `json:"documents"`
+	UpdatedAt time.Time            `json:"updated_at"`
+
+	postings    map[string]map[string]int // term -> document ID -> frequency
+	totalLength int
+}
+
+func NewIndex() *Index {
+	return &Index{
+		Documents: make(map[string]*Document),
+		postings:  make(map[string]map[string]int),
+	}
+}
+
+// Load reads the index saved at path, or returns an empty one if there is
+// none yet.
+func Load(path string) (*Index, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewIndex(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	index := NewIndex()
+	if err := json.Unmarshal(data, index); err != nil {
+		return nil, fmt.Errorf("corrupt search index in %s: %w", path, err)
+	}
+	if index.Documents == nil {
+		index.Documents = make(map[string]*Document)
+	}
+	for _, document := range index.Documents {
+		index.addPostings(document)
+	}
+	return index, nil
+}
+
+// Save writes the index to a temporary file and renames it over path, so
+// that a search running at the same time never reads half an index.
+func (ix *Index) Save(path string) error {
+	data, err := json.Marshal(ix)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// SyncTasks indexes the current tasks, skipping those that did not change,
+// and drops current tasks that are gone. It returns how many documents
+// changed.
+func (ix *Index) SyncTasks(tasks *[]adapters.Task) int {
+	changed := 0
+	seen := make(map[string]bool)
+	for i := range *tasks {
+		seen[(*tasks)[i].ID] = true
+		if ix.put(&(*tasks)[i], false) {
+			changed++
+		}
+	}
+	for id, document := range ix.Documents {
+		if !document.Archived && !seen[id] {
+			ix.remove(id)
+			changed++
+		}
+	}
+	return changed
+}
+
+// AddArchived indexes completed tasks. A task that was indexed while still
+// current keeps its description and notes, which the archive lacks.
+func (ix *Index) AddArchived(tasks *[]adapters.Task) int {
+	changed := 0
+	for i := range *tasks {
+		task := &(*tasks)[i]
+		if document, ok := ix.Documents[task.ID]; ok && !document.Archived {
+			document.Archived = true
+			if task.CompletedAt != nil {
+				document.Date = *task.CompletedAt
+			}
+			changed++
+			continue
+		}
+		if ix.put(task, true) {
+			changed++
+		}
+	}
+	return changed
+}
+
+func (ix *Index) put(task *adapters.Task, archived bool) bool {
+	text := strings.Join(append([]string{task.Content, task.Description, task.Project}, task.Notes...), "\n")
+	hash := fnv.New64a()
+	hash.Write([]byte(text))
+	fingerprint := hash.Sum64()
+
+	existing, ok := ix.Documents[task.ID]
+	if ok && existing.Fingerprint == fingerprint && existing.Archived == archived {
+		return false
+	}
+	if ok {
+		ix.remove(task.ID)
+	}
+
+	date := task.CreatedDate
+	if archived && task.CompletedAt != nil {
+		date = *task.CompletedAt
+	}
+	document := &Document{
+		ID:          task.ID,
+		Content:     task.Content,
+		Project:     task.Project,
+		Archived:    archived,
+		Date:        date,
+		Fingerprint: fingerprint,
+		Terms:       make(map[string]int),
+	}
+	for _, term := range Terms(text) {
+		document.Terms[term]++
+		document.Length++
+	}
+	ix.Documents[task.ID] = document
+	ix.addPostings(document)
+	return true
+}
+
+func (ix *Index) remove(id string) {
+	document, ok := ix.Documents[id]
+	if !ok {
+		return
+	}
+	for term := range document.Terms {
+		delete(ix.postings[term], id)
+		if len(ix.postings[term]) == 0 {
+			delete(ix.postings, term)
+		}
+	}
+	ix.totalLength -= document.Length
+	delete(ix.Documents, id)
+}
+
+func (ix *Index) addPostings(document *Document) {
+	for term, frequency := range document.Terms {
+		if ix.postings[term] == nil {
+			ix.postings[term] = make(map[string]int)
+		}
+		ix.postings[term][document.ID] = frequency
+	}
+	ix.totalLength += document.Length
+}
+
+// Search ranks documents against text with BM25. Query words also match
+// indexed words they are a prefix of, or that are within a typo or two of
+// them, with a lower weight.
+func (ix *Index) Search(text string, limit int) []Result {
+	if len(ix.Documents) == 0 {
+		return nil
+	}
+	averageLength := float64(ix.totalLength) / float64(len(ix.Documents))
+
+	scores := make(map[string]float64)
+	for _, queryTerm := range Terms(text) {
+		termScores := make(map[string]float64)
+		for term, weight := range ix.expand(queryTerm) {
+			postings := ix.postings[term]
+			idf := math.Log(1 + (float64(len(ix.Documents))-float64(len(postings))+0.5)/(float64(len(postings))+0.5))
+			for id, frequency := range postings {
+				tf := float64(frequency)
+				length := float64(ix.Documents[id].Length)
+				score := weight * idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*length/averageLength))
+				termScores[id] = max(termScores[id], score)
+			}
+		}
+		for id, score := range termScores {
+			scores[id] += score
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for id, score := range scores {
+		results = append(results, Result{Document: ix.Documents[id], Score: score})
+	}
+	slices.SortFunc(results, func(a, b Result) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		return b.Document.Date.Compare(a.Document.Date)
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// expand returns the indexed terms a query term matches, with their weight.
+func (ix *Index) expand(queryTerm string) map[string]float64 {
+	matches := make(map[string]float64)
+	if _, ok := ix.postings[queryTerm]; ok {
+		matches[queryTerm] = 1
+	}
+	typos := maxTypos(queryTerm)
+	for term := range ix.postings {
+		if term == queryTerm {
+			continue
+		}
+		switch {
+		case len(queryTerm) >= 3 && strings.HasPrefix(term, queryTerm):
+			matches[term] = prefixWeight
+		case typos > 0 && abs(len(term)-len(queryTerm)) <= typos && editDistance(queryTerm, term) <= typos:
+			matches[term] = fuzzyWeight
+		}
+	}
+	return matches
+}
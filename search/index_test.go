@@ -0,0 +1,41 @@
+package search
+
+import (
+	"github.com/dormunis/gitd/adapters"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "gitd", "search-index.json")
+	index := NewIndex()
+	index.SyncTasks(&[]adapters.Task{{ID: "1", Content: "Renew passport", Project: "Personal"}})
+	index.UpdatedAt = time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		if err := index.Save(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("got %d files, want only the index", len(entries))
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.UpdatedAt.Equal(index.UpdatedAt) {
+		t.Errorf("got updated at %s, want %s", loaded.UpdatedAt, index.UpdatedAt)
+	}
+	if results := loaded.Search("passport", 10); len(results) != 1 || results[0].Document.ID != "1" {
+		t.Errorf("got %+v, want the saved task", results)
+	}
+}
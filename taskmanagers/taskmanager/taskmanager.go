@@ -1,19 +1,38 @@
 package taskmanager
 
 import (
-	"errors"
+	"cmp"
 	"fmt"
 	"github.com/dormunis/gitd/adapters"
+	"github.com/dormunis/gitd/taskmanagers/taskwarrior"
 	"github.com/dormunis/gitd/taskmanagers/todoist"
+	"path"
+	"slices"
+	"strings"
 	"time"
 )
 
 type TaskManagerAdapterType string
 
 const (
-	Todoist TaskManagerAdapterType = "todoist"
+	Todoist     TaskManagerAdapterType = "todoist"
+	Taskwarrior TaskManagerAdapterType = "taskwarrior"
 )
 
+// TaskManagerTypes are the task managers Initialize knows.
+var TaskManagerTypes = []TaskManagerAdapterType{Todoist, Taskwarrior}
+
+func ParseTaskManagerType(input string) (TaskManagerAdapterType, error) {
+	names := make([]string, len(TaskManagerTypes))
+	for i, taskManagerType := range TaskManagerTypes {
+		if strings.EqualFold(input, string(taskManagerType)) {
+			return taskManagerType, nil
+		}
+		names[i] = string(taskManagerType)
+	}
+	return "", adapters.Errorf(adapters.ErrorValidation, "unknown task manager %s, expected one of %s", input, strings.Join(names, ", "))
+}
+
 type TaskManager interface {
 	Initialize(adapters.Settings) error
 	FetchTasks() ([]adapters.Task, error)
@@ -29,33 +48,240 @@ func Initialize(taskManagerAdapterType TaskManagerAdapterType, settings adapters
 		var e error
 		adapter, e = todoist.NewTodoistAdapter()
 
+		if e != nil {
+			return nil, e
+		}
+	case Taskwarrior:
+		var e error
+		adapter, e = taskwarrior.NewTaskwarriorAdapter()
+
 		if e != nil {
 			return nil, e
 		}
 	default:
-		return nil, errors.New(fmt.Sprintf("Unknown adapter type: %v", taskManagerAdapterType))
+		return nil, adapters.Errorf(adapters.ErrorConfig, "Unknown adapter type: %v", taskManagerAdapterType)
+	}
+	if err := adapter.Initialize(settings); err != nil {
+		return nil, err
 	}
-	adapter.Initialize(settings)
 	return adapter, nil
 }
 
 func FilterTasks(tasks *[]adapters.Task, fr *adapters.FilterRequest) ([]adapters.Task, error) {
+	now := time.Now()
+	if fr.Location != nil {
+		now = now.In(fr.Location)
+	}
 	var filtered []adapters.Task
 	for _, task := range *tasks {
-		if fr.Project != nil && task.Project != *fr.Project {
+		matches, err := matchesFilter(&task, fr, now)
+		if err != nil {
+			return nil, err
+		}
+		if matches {
+			filtered = append(filtered, task)
+		}
+	}
+	return filtered, nil
+}
+
+func matchesFilter(task *adapters.Task, fr *adapters.FilterRequest, now time.Time) (bool, error) {
+	if fr.Projects != nil {
+		matched, err := matchesAnyGlob(task.Project, *fr.Projects)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	if fr.Statuses != nil && !slices.Contains(*fr.Statuses, task.Status) {
+		return false, nil
+	}
+	if fr.ExcludedStatuses != nil && slices.Contains(*fr.ExcludedStatuses, task.Status) {
+		return false, nil
+	}
+	if fr.MinPriority != nil && task.Priority < *fr.MinPriority {
+		return false, nil
+	}
+	if fr.MaxPriority != nil && task.Priority > *fr.MaxPriority {
+		return false, nil
+	}
+	if fr.AnyTags != nil && !slices.ContainsFunc(*fr.AnyTags, func(tag string) bool {
+		return slices.Contains(task.Tags, tag)
+	}) {
+		return false, nil
+	}
+	if fr.AllTags != nil && slices.ContainsFunc(*fr.AllTags, func(tag string) bool {
+		return !slices.Contains(task.Tags, tag)
+	}) {
+		return false, nil
+	}
+	if fr.NoTags != nil && slices.ContainsFunc(*fr.NoTags, func(tag string) bool {
+		return slices.Contains(task.Tags, tag)
+	}) {
+		return false, nil
+	}
+	if fr.CreatedAfter != nil && task.CreatedDate.Before(fr.CreatedAfter.ModifyDate(now, false)) {
+		return false, nil
+	}
+	if fr.CreatedBefore != nil && !task.CreatedDate.Before(fr.CreatedBefore.ModifyDate(now, false)) {
+		return false, nil
+	}
+	if fr.UpdatedAfter != nil && task.UpdatedDate.Before(fr.UpdatedAfter.ModifyDate(now, false)) {
+		return false, nil
+	}
+	if fr.UpdatedBefore != nil && !task.UpdatedDate.Before(fr.UpdatedBefore.ModifyDate(now, false)) {
+		return false, nil
+	}
+	if fr.UpdatedSince != nil && task.UpdatedDate.Before(*fr.UpdatedSince) {
+		return false, nil
+	}
+	if fr.UpdatedUntil != nil && !task.UpdatedDate.Before(*fr.UpdatedUntil) {
+		return false, nil
+	}
+	if fr.MaxDuration != nil && task.Duration != nil && *task.Duration > *fr.MaxDuration {
+		return false, nil
+	}
+	if fr.MaxEnergy != nil && task.Energy > *fr.MaxEnergy {
+		return false, nil
+	}
+	if fr.Query != nil && !fr.Query.Matches(task, now) {
+		return false, nil
+	}
+	return true, nil
+}
+
+func matchesAnyGlob(name string, patterns []string) (bool, error) {
+	for _, pattern := range patterns {
+		matched, err := path.Match(pattern, name)
+		if err != nil {
+			return false, fmt.Errorf("invalid project pattern %q: %w", pattern, err)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+var sortKeys = map[string]func(a, b *adapters.Task) int{
+	"priority": func(a, b *adapters.Task) int { return cmp.Compare(a.Priority, b.Priority) },
+	"status":   func(a, b *adapters.Task) int { return cmp.Compare(a.Status, b.Status) },
+	"project":  func(a, b *adapters.Task) int { return strings.Compare(a.Project, b.Project) },
+	"content":  func(a, b *adapters.Task) int { return strings.Compare(a.Content, b.Content) },
+	"created":  func(a, b *adapters.Task) int { return a.CreatedDate.Compare(b.CreatedDate) },
+	"updated":  func(a, b *adapters.Task) int { return a.UpdatedDate.Compare(b.UpdatedDate) },
+	"due":      compareDueDates,
+}
+
+// compareDueDates orders tasks without a due date after those with one.
+func compareDueDates(a, b *adapters.Task) int {
+	switch {
+	case a.DueDate == nil && b.DueDate == nil:
+		return 0
+	case a.DueDate == nil:
+		return 1
+	case b.DueDate == nil:
+		return -1
+	default:
+		return a.DueDate.Compare(*b.DueDate)
+	}
+}
+
+// SortTasks orders tasks by the given keys in turn. A key prefixed with "-"
+// sorts in descending order, e.g. []string{"priority", "-updated"}.
+func SortTasks(tasks *[]adapters.Task, keys []string) error {
+	var comparators []func(a, b *adapters.Task) int
+	for _, key := range keys {
+		descending := strings.HasPrefix(key, "-")
+		compare, ok := sortKeys[strings.TrimPrefix(key, "-")]
+		if !ok {
+			return fmt.Errorf("unknown sort key: %s", key)
+		}
+		if descending {
+			ascending := compare
+			compare = func(a, b *adapters.Task) int { return ascending(b, a) }
+		}
+		comparators = append(comparators, compare)
+	}
+
+	slices.SortStableFunc(*tasks, func(a, b adapters.Task) int {
+		for _, compare := range comparators {
+			if result := compare(&a, &b); result != 0 {
+				return result
+			}
+		}
+		return 0
+	})
+	return nil
+}
+
+type ProjectAudit struct {
+	Project           adapters.Project `json:"project"`
+	TaskCount         int              `json:"task_count"`
+	NextActionCount   int              `json:"next_action_count"`
+	LastActivity      *time.Time       `json:"last_activity,omitempty"`
+	MissingNextAction bool             `json:"missing_next_action"`
+	Untouched         bool             `json:"untouched"`
+}
+
+// AuditProjects finds active projects that have no next action, or whose
+// tasks have all gone untouched for longer than untouchedFor. The inbox,
+// archived projects and projects that only group sub-projects are skipped.
+// Results are ordered by last activity, oldest first.
+func AuditProjects(projects *[]adapters.Project, tasks *[]adapters.Task, untouchedFor adapters.TimeSpan, now time.Time) []ProjectAudit {
+	hasChildren := make(map[string]bool)
+	for _, project := range *projects {
+		if project.ParentID != "" {
+			hasChildren[project.ParentID] = true
+		}
+	}
+
+	audits := make(map[string]*ProjectAudit)
+	for _, project := range *projects {
+		if project.Inbox || project.Archived {
 			continue
 		}
-		if fr.Status != nil && task.Status == *fr.Status {
+		audits[project.ID] = &ProjectAudit{Project: project, LastActivity: project.CreatedDate}
+	}
+
+	for i := range *tasks {
+		task := &(*tasks)[i]
+		audit, ok := audits[task.ProjectID]
+		if !ok || task.Status == adapters.StatusCompleted || task.Status == adapters.StatusDeleted {
 			continue
 		}
-		if fr.AfterTimeSpan != nil {
-			wantedDate := (*fr.AfterTimeSpan).ModifyDate(time.Now(), false)
-			if task.UpdatedDate.Before(wantedDate) {
-				continue
-			}
+		audit.TaskCount++
+		if task.Status == adapters.StatusNext {
+			audit.NextActionCount++
+		}
+		if audit.LastActivity == nil || task.UpdatedDate.After(*audit.LastActivity) {
+			audit.LastActivity = &task.UpdatedDate
 		}
-		// TOOD: implement tags filtering
-		filtered = append(filtered, task)
 	}
-	return filtered, nil
+
+	threshold := untouchedFor.ModifyDate(now, false)
+	var flagged []ProjectAudit
+	for _, audit := range audits {
+		if hasChildren[audit.Project.ID] && audit.TaskCount == 0 {
+			continue
+		}
+		audit.MissingNextAction = audit.NextActionCount == 0
+		audit.Untouched = !untouchedFor.IsZero() && audit.LastActivity != nil && audit.LastActivity.Before(threshold)
+		if audit.MissingNextAction || audit.Untouched {
+			flagged = append(flagged, *audit)
+		}
+	}
+
+	slices.SortFunc(flagged, func(a, b ProjectAudit) int {
+		switch {
+		case a.LastActivity == nil && b.LastActivity == nil:
+			return strings.Compare(a.Project.Name, b.Project.Name)
+		case a.LastActivity == nil:
+			return -1
+		case b.LastActivity == nil:
+			return 1
+		default:
+			return a.LastActivity.Compare(*b.LastActivity)
+		}
+	})
+	return flagged
 }
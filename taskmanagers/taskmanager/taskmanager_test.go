@@ -0,0 +1,95 @@
+package taskmanager
+
+import (
+	"github.com/dormunis/gitd/adapters"
+	"testing"
+	"time"
+)
+
+func TestMatchesFilter(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	task := adapters.Task{
+		Content:     "Renew passport",
+		Project:     "Home/Admin",
+		Status:      adapters.StatusNext,
+		Priority:    adapters.PriorityHigh,
+		Tags:        []string{"errand", "phone"},
+		CreatedDate: now.AddDate(0, -2, 0),
+		UpdatedDate: now.AddDate(0, 0, -10),
+	}
+	statuses := func(values ...adapters.Status) *[]adapters.Status { return &values }
+	tags := func(values ...string) *[]string { return &values }
+	priority := func(value adapters.Priority) *adapters.Priority { return &value }
+	timespan := func(value string) *adapters.TimeSpan {
+		timespan, err := adapters.NewTimeSpan(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return timespan
+	}
+	date := func(year int, month time.Month, day int) *time.Time {
+		date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		return &date
+	}
+
+	tests := []struct {
+		name   string
+		filter adapters.FilterRequest
+		want   bool
+	}{
+		{"empty", adapters.FilterRequest{}, true},
+
+		{"any tag", adapters.FilterRequest{AnyTags: tags("home", "phone")}, true},
+		{"any tag missing", adapters.FilterRequest{AnyTags: tags("home", "computer")}, false},
+		{"all tags", adapters.FilterRequest{AllTags: tags("errand", "phone")}, true},
+		{"all tags missing one", adapters.FilterRequest{AllTags: tags("errand", "home")}, false},
+		{"no tag", adapters.FilterRequest{NoTags: tags("home")}, true},
+		{"no tag present", adapters.FilterRequest{NoTags: tags("home", "phone")}, false},
+
+		{"status", adapters.FilterRequest{Statuses: statuses(adapters.StatusActive, adapters.StatusNext)}, true},
+		{"status missing", adapters.FilterRequest{Statuses: statuses(adapters.StatusSomeday)}, false},
+		{"excluded status", adapters.FilterRequest{ExcludedStatuses: statuses(adapters.StatusNext)}, false},
+		{"other excluded status", adapters.FilterRequest{ExcludedStatuses: statuses(adapters.StatusCompleted)}, true},
+
+		{"at least critical", adapters.FilterRequest{MinPriority: priority(adapters.PriorityCritical)}, true},
+		{"at least medium", adapters.FilterRequest{MinPriority: priority(adapters.PriorityMedium)}, false},
+		{"at most high", adapters.FilterRequest{MaxPriority: priority(adapters.PriorityHigh)}, true},
+		{"at most critical", adapters.FilterRequest{MaxPriority: priority(adapters.PriorityCritical)}, false},
+		{"priority range", adapters.FilterRequest{MinPriority: priority(adapters.PriorityCritical), MaxPriority: priority(adapters.PriorityMedium)}, true},
+
+		{"project", adapters.FilterRequest{Projects: tags("Home/Admin")}, true},
+		{"project glob", adapters.FilterRequest{Projects: tags("Work*", "Home/*")}, true},
+		{"project glob missing", adapters.FilterRequest{Projects: tags("Home")}, false},
+
+		{"created within", adapters.FilterRequest{CreatedAfter: timespan("3 months")}, true},
+		{"created within too short", adapters.FilterRequest{CreatedAfter: timespan("1 month")}, false},
+		{"created before", adapters.FilterRequest{CreatedBefore: timespan("1 month")}, true},
+		{"updated within", adapters.FilterRequest{UpdatedAfter: timespan("2 weeks")}, true},
+		{"not updated within", adapters.FilterRequest{UpdatedBefore: timespan("1 week")}, true},
+		{"updated within a week", adapters.FilterRequest{UpdatedBefore: timespan("2 weeks")}, false},
+		{"updated since", adapters.FilterRequest{UpdatedSince: date(2026, 3, 1)}, true},
+		{"updated since later", adapters.FilterRequest{UpdatedSince: date(2026, 3, 10)}, false},
+		{"updated until", adapters.FilterRequest{UpdatedUntil: date(2026, 3, 10)}, true},
+		{"updated until earlier", adapters.FilterRequest{UpdatedUntil: date(2026, 3, 1)}, false},
+		{"updated window", adapters.FilterRequest{UpdatedSince: date(2026, 3, 1), UpdatedUntil: date(2026, 3, 10)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := matchesFilter(&task, &tt.filter, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesFilterInvalidGlob(t *testing.T) {
+	patterns := []string{"[Home"}
+	_, err := matchesFilter(&adapters.Task{Project: "Home"}, &adapters.FilterRequest{Projects: &patterns}, time.Now())
+	if err == nil {
+		t.Error("got no error for an invalid pattern")
+	}
+}
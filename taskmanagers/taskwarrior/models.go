@@ -0,0 +1,175 @@
+package taskwarrior
+
+import (
+	"encoding/json"
+	"github.com/dormunis/gitd/adapters"
+	"slices"
+	"time"
+)
+
+const (
+	timeLayout       = "20060102T150405Z"
+	descriptionField = "gitd_description" // user defined attribute holding the description
+	waitingLabel     = "waiting_for"
+)
+
+// statusLabels are the tags that carry a GTD status, as the Todoist labels do.
+var statusLabels = map[adapters.Status]string{
+	adapters.StatusNext:    "next",
+	adapters.StatusSomeday: "someday_maybe",
+	adapters.StatusWaiting: waitingLabel,
+}
+
+// Item is a task as written by task export. The attributes gitd does not know
+// are kept in raw, so that importing the task back does not drop them.
+type Item struct {
+	UUID        string       `json:"uuid"`
+	Description string       `json:"description"`
+	Project     string       `json:"project,omitempty"`
+	Status      string       `json:"status"`
+	Tags        []string     `json:"tags,omitempty"`
+	Priority    string       `json:"priority,omitempty"`
+	Entry       string       `json:"entry,omitempty"`
+	Modified    string       `json:"modified,omitempty"`
+	End         string       `json:"end,omitempty"`
+	Due         string       `json:"due,omitempty"`
+	Annotations []Annotation `json:"annotations,omitempty"`
+	Notes       string       `json:"gitd_description,omitempty"`
+
+	raw map[string]json.RawMessage
+}
+
+type Annotation struct {
+	Entry       string `json:"entry"`
+	Description string `json:"description"`
+}
+
+func (i *Item) UnmarshalJSON(data []byte) error {
+	type item Item
+	if err := json.Unmarshal(data, (*item)(i)); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &i.raw)
+}
+
+// MarshalJSON writes the attributes gitd knows over the ones it kept.
+// Attributes Taskwarrior computes on export are left out.
+func (i Item) MarshalJSON() ([]byte, error) {
+	type item Item
+	known, err := json.Marshal(item(i))
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(known, &fields); err != nil {
+		return nil, err
+	}
+	merged := make(map[string]json.RawMessage, len(i.raw)+len(fields))
+	for key, value := range i.raw {
+		merged[key] = value
+	}
+	for _, key := range []string{"id", "urgency", "project", "tags", "priority", "end", "due", "annotations", descriptionField} {
+		delete(merged, key)
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return json.Marshal(merged)
+}
+
+func (i *Item) ToTask() adapters.Task {
+	task := adapters.Task{
+		ID:          i.UUID,
+		ProjectID:   i.Project,
+		Project:     i.Project,
+		Inbox:       i.Project == "",
+		Content:     i.Description,
+		Description: i.Notes,
+		CreatedDate: parseTime(i.Entry),
+		UpdatedDate: parseTime(i.Modified),
+		Tags:        i.Tags,
+		Status:      deriveStatus(i),
+		Priority:    toPriority(i.Priority),
+		Waiting:     adapters.ParseWaitingInfo(i.Notes),
+		TaskManger:  "taskwarrior",
+	}
+	if task.Inbox {
+		task.Project = "Inbox"
+	}
+	if task.UpdatedDate.IsZero() {
+		task.UpdatedDate = task.CreatedDate
+	}
+	if task.Tags == nil {
+		task.Tags = []string{}
+	}
+	for _, annotation := range i.Annotations {
+		task.Notes = append(task.Notes, annotation.Description)
+		if entry := parseTime(annotation.Entry); entry.After(task.UpdatedDate) {
+			task.UpdatedDate = entry
+		}
+	}
+	if i.Due != "" {
+		due := parseTime(i.Due).Local()
+		task.DueDate = &due
+	}
+	if i.End != "" && i.Status == "completed" {
+		end := parseTime(i.End)
+		task.CompletedAt = &end
+	}
+	return task
+}
+
+func deriveStatus(item *Item) adapters.Status {
+	switch item.Status {
+	case "completed":
+		return adapters.StatusCompleted
+	case "deleted":
+		return adapters.StatusDeleted
+	}
+	for _, status := range []adapters.Status{adapters.StatusWaiting, adapters.StatusNext, adapters.StatusSomeday} {
+		if slices.Contains(item.Tags, statusLabels[status]) {
+			return status
+		}
+	}
+	return adapters.StatusActive
+}
+
+// toPriority maps Taskwarrior's H, M and L onto the three most urgent
+// priorities, leaving tasks without one at the lowest, as Todoist does.
+func toPriority(priority string) adapters.Priority {
+	switch priority {
+	case "H":
+		return adapters.PriorityCritical
+	case "M":
+		return adapters.PriorityHigh
+	case "L":
+		return adapters.PriorityMedium
+	default:
+		return adapters.PriorityLow
+	}
+}
+
+func fromPriority(priority adapters.Priority) string {
+	switch priority {
+	case adapters.PriorityCritical:
+		return "H"
+	case adapters.PriorityHigh:
+		return "M"
+	case adapters.PriorityMedium:
+		return "L"
+	default:
+		return ""
+	}
+}
+
+func parseTime(value string) time.Time {
+	parsed, err := time.Parse(timeLayout, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return parsed
+}
+
+func formatTime(value time.Time) string {
+	return value.UTC().Format(timeLayout)
+}
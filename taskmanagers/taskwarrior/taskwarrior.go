@@ -0,0 +1,327 @@
+package taskwarrior
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/dormunis/gitd/adapters"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultCommand   = "task"
+	revalidationNote = "Revalidated on "
+)
+
+// TaskwarriorAdapter drives the task command line, reading tasks with
+// task export and writing them back with task import.
+type TaskwarriorAdapter struct {
+	command string
+	run     func(input []byte, args ...string) ([]byte, error)
+}
+
+func (t *TaskwarriorAdapter) Initialize(settings adapters.Settings) error {
+	t.command = settings.Taskwarrior.Command
+	if t.command == "" {
+		t.command = defaultCommand
+	}
+	if _, err := exec.LookPath(t.command); err != nil {
+		return adapters.Errorf(adapters.ErrorConfig, "taskwarrior not found: %w", err)
+	}
+	t.run = t.execute
+	return nil
+}
+
+func (t *TaskwarriorAdapter) execute(input []byte, args ...string) ([]byte, error) {
+	args = append([]string{"rc.confirmation=off", "rc.verbose=nothing", "rc.json.array=on"}, args...)
+	cmd := exec.Command(t.command, args...)
+	cmd.Stdin = bytes.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, adapters.Errorf(adapters.ErrorAPI, "%s %s failed: %w: %s", t.command, args[len(args)-1], err, strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
+
+func (t *TaskwarriorAdapter) export(filter ...string) ([]Item, error) {
+	output, err := t.run(nil, append(filter, "export")...)
+	if err != nil {
+		return nil, err
+	}
+	var items []Item
+	if err := json.Unmarshal(output, &items); err != nil {
+		return nil, adapters.Errorf(adapters.ErrorAPI, "error decoding task export: %w", err)
+	}
+	return items, nil
+}
+
+func (t *TaskwarriorAdapter) importItems(items []Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	_, err = t.run(data, "import", "-")
+	return err
+}
+
+// pending exports the tasks that are neither done nor recurrence templates.
+func (t *TaskwarriorAdapter) pending() ([]Item, error) {
+	items, err := t.export()
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(items, func(item Item) bool {
+		return item.Status != "pending" && item.Status != "waiting"
+	}), nil
+}
+
+func (t *TaskwarriorAdapter) FetchTasks() ([]adapters.Task, error) {
+	items, err := t.pending()
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]adapters.Task, 0, len(items))
+	for i := range items {
+		tasks = append(tasks, items[i].ToTask())
+	}
+	return tasks, nil
+}
+
+// FetchProjects returns the projects of pending tasks, as Taskwarrior has no
+// list of projects of its own.
+func (t *TaskwarriorAdapter) FetchProjects() ([]adapters.Project, error) {
+	items, err := t.pending()
+	if err != nil {
+		return nil, err
+	}
+	var projects []adapters.Project
+	for _, item := range items {
+		if item.Project == "" || slices.ContainsFunc(projects, func(project adapters.Project) bool { return project.ID == item.Project }) {
+			continue
+		}
+		projects = append(projects, adapters.Project{ID: item.Project, Name: item.Project})
+	}
+	slices.SortFunc(projects, func(a, b adapters.Project) int { return strings.Compare(a.Name, b.Name) })
+	return projects, nil
+}
+
+func (t *TaskwarriorAdapter) FetchCompletedTasks(since time.Time, until time.Time) ([]adapters.Task, error) {
+	items, err := t.export("status:completed")
+	if err != nil {
+		return nil, err
+	}
+	var tasks []adapters.Task
+	for i := range items {
+		task := items[i].ToTask()
+		if task.CompletedAt != nil && !task.CompletedAt.Before(since) && task.CompletedAt.Before(until) {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+func (t *TaskwarriorAdapter) UpdateTasks(actions *[]adapters.TaskAction) error {
+	var edits []adapters.TaskEdit
+	note := revalidationNote + time.Now().Format("2006-01-02")
+	someday := adapters.StatusSomeday
+	for _, action := range *actions {
+		switch action.Action {
+		case adapters.ActionComplete:
+			edits = append(edits, adapters.TaskEdit{Task: action.Task, Complete: true})
+		case adapters.ActionDelete:
+			edits = append(edits, adapters.TaskEdit{Task: action.Task, Delete: true})
+		case adapters.ActionDefer:
+			edits = append(edits, adapters.TaskEdit{Task: action.Task, Status: &someday})
+		case adapters.ActionRevalidate:
+			edits = append(edits, adapters.TaskEdit{Task: action.Task, Note: &note})
+		}
+	}
+	return t.EditTasks(&edits)
+}
+
+// EditTasks applies the edits to the tasks as they are now and imports them
+// back in one go.
+func (t *TaskwarriorAdapter) EditTasks(edits *[]adapters.TaskEdit) error {
+	if len(*edits) == 0 {
+		return nil
+	}
+	items, err := t.pending()
+	if err != nil {
+		return err
+	}
+	byUUID := make(map[string]*Item, len(items))
+	for i := range items {
+		byUUID[items[i].UUID] = &items[i]
+	}
+
+	now := time.Now()
+	var created []Item
+	var changed, missing []string
+	for _, edit := range *edits {
+		item, ok := byUUID[edit.Task.ID]
+		if !ok {
+			missing = append(missing, edit.Task.ID)
+			continue
+		}
+		subtasks, err := applyEdit(item, &edit, now)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(changed, item.UUID) {
+			changed = append(changed, item.UUID)
+		}
+		created = append(created, subtasks...)
+	}
+	if len(missing) > 0 {
+		return adapters.Errorf(adapters.ErrorValidation, "tasks not found in taskwarrior: %s", strings.Join(missing, ", "))
+	}
+	for _, id := range changed {
+		created = append(created, *byUUID[id])
+	}
+	return t.importItems(created)
+}
+
+// applyEdit changes item as the edit describes, returning the subtasks to
+// create, which the item then depends on.
+func applyEdit(item *Item, edit *adapters.TaskEdit, now time.Time) ([]Item, error) {
+	item.Modified = formatTime(now)
+	if edit.Delete {
+		item.Status = "deleted"
+		item.End = formatTime(now)
+		return nil, nil
+	}
+
+	if edit.Content != nil {
+		item.Description = *edit.Content
+	}
+	if edit.Description != nil {
+		item.Notes = *edit.Description
+	}
+	if edit.ProjectID != nil {
+		item.Project = *edit.ProjectID
+	}
+	if edit.Priority != nil {
+		item.Priority = fromPriority(*edit.Priority)
+	}
+	if edit.Due != nil {
+		due, err := adapters.ParseDueDate(*edit.Due, now)
+		if err != nil {
+			return nil, adapters.Errorf(adapters.ErrorValidation, "invalid due date for %q: %w", item.Description, err)
+		}
+		item.Due = formatTime(due)
+	}
+
+	addTags, removeTags := edit.AddTags, edit.RemoveTags
+	if edit.Status != nil {
+		for status, label := range statusLabels {
+			if status == *edit.Status {
+				addTags = append(slices.Clone(addTags), label)
+			} else {
+				removeTags = append(slices.Clone(removeTags), label)
+			}
+		}
+	}
+	tags := slices.DeleteFunc(slices.Clone(item.Tags), func(tag string) bool { return slices.Contains(removeTags, tag) })
+	for _, tag := range addTags {
+		if !slices.Contains(tags, tag) && !slices.Contains(removeTags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	item.Tags = tags
+
+	if edit.Note != nil {
+		item.Annotations = append(item.Annotations, Annotation{Entry: formatTime(now), Description: *edit.Note})
+	}
+	if edit.Complete {
+		item.Status = "completed"
+		item.End = formatTime(now)
+	}
+
+	var subtasks []Item
+	for _, content := range edit.Subtasks {
+		subtask := newItem(content, now)
+		subtask.Project = item.Project
+		subtasks = append(subtasks, subtask)
+	}
+	if len(subtasks) > 0 {
+		var depends []string
+		if raw, ok := item.raw["depends"]; ok {
+			json.Unmarshal(raw, &depends)
+		}
+		for _, subtask := range subtasks {
+			depends = append(depends, subtask.UUID)
+		}
+		data, err := json.Marshal(depends)
+		if err != nil {
+			return nil, err
+		}
+		if item.raw == nil {
+			item.raw = map[string]json.RawMessage{}
+		}
+		item.raw["depends"] = data
+	}
+	return subtasks, nil
+}
+
+func (t *TaskwarriorAdapter) CreateTasks(drafts *[]adapters.TaskDraft) error {
+	now := time.Now()
+	items := make([]Item, 0, len(*drafts))
+	for _, draft := range *drafts {
+		item := newItem(draft.Content, now)
+		item.Notes = draft.Description
+		item.Project = draft.ProjectID
+		item.Tags = slices.Clone(draft.Tags)
+		if draft.Priority != 0 {
+			item.Priority = fromPriority(draft.Priority)
+		}
+		if draft.Due != nil {
+			due, err := adapters.ParseDueDate(*draft.Due, now)
+			if err != nil {
+				return adapters.Errorf(adapters.ErrorValidation, "invalid due date for %q: %w", draft.Content, err)
+			}
+			item.Due = formatTime(due)
+		}
+		items = append(items, item)
+	}
+	return t.importItems(items)
+}
+
+func newItem(content string, now time.Time) Item {
+	return Item{
+		UUID:        uuid.New().String(),
+		Description: content,
+		Status:      "pending",
+		Entry:       formatTime(now),
+		Modified:    formatTime(now),
+	}
+}
+
+// CreateProjects returns the projects of the given names, which exist as
+// soon as a task refers to them.
+func (t *TaskwarriorAdapter) CreateProjects(names []string) ([]adapters.Project, error) {
+	projects := make([]adapters.Project, 0, len(names))
+	for _, name := range names {
+		projects = append(projects, adapters.Project{ID: name, Name: name})
+	}
+	return projects, nil
+}
+
+func (t *TaskwarriorAdapter) ArchiveProjects(projects *[]adapters.Project) error {
+	if len(*projects) == 0 {
+		return nil
+	}
+	return adapters.Errorf(adapters.ErrorValidation, "taskwarrior projects cannot be archived, complete their tasks instead")
+}
+
+func NewTaskwarriorAdapter() (adapters.TaskManagerAdapter, error) {
+	return &TaskwarriorAdapter{}, nil
+}
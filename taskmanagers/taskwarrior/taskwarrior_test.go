@@ -0,0 +1,237 @@
+package taskwarrior
+
+import (
+	"encoding/json"
+	"github.com/dormunis/gitd/adapters"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// fakeTaskwarrior keeps tasks in memory, answering export and import the way
+// the task command does.
+type fakeTaskwarrior struct {
+	tasks   []map[string]json.RawMessage
+	imports int
+}
+
+func (f *fakeTaskwarrior) run(input []byte, args ...string) ([]byte, error) {
+	switch args[len(args)-1] {
+	case "export":
+		exported := []map[string]json.RawMessage{}
+		for i, task := range f.tasks {
+			if slices.Contains(args, "status:completed") && string(task["status"]) != `"completed"` {
+				continue
+			}
+			exported = append(exported, task)
+			exported[len(exported)-1]["id"] = json.RawMessage(strconv.Itoa(i + 1))
+		}
+		return json.Marshal(exported)
+	case "-":
+		f.imports++
+		var imported []map[string]json.RawMessage
+		if err := json.Unmarshal(input, &imported); err != nil {
+			return nil, err
+		}
+		for _, task := range imported {
+			index := slices.IndexFunc(f.tasks, func(other map[string]json.RawMessage) bool {
+				return string(other["uuid"]) == string(task["uuid"])
+			})
+			if index == -1 {
+				f.tasks = append(f.tasks, task)
+			} else {
+				f.tasks[index] = task
+			}
+		}
+		return nil, nil
+	}
+	return nil, nil
+}
+
+func newTestAdapter(t *testing.T, exported string) (*TaskwarriorAdapter, *fakeTaskwarrior) {
+	fake := &fakeTaskwarrior{}
+	if err := json.Unmarshal([]byte(exported), &fake.tasks); err != nil {
+		t.Fatal(err)
+	}
+	return &TaskwarriorAdapter{command: "task", run: fake.run}, fake
+}
+
+const exported = `[
+	{"uuid": "a", "description": "Call the plumber", "project": "Home", "status": "pending", "tags": ["phone", "next"],
+	 "priority": "H", "entry": "20260101T090000Z", "modified": "20260102T090000Z", "due": "20260110T090000Z",
+	 "annotations": [{"entry": "20260103T090000Z", "description": "left a message"}], "urgency": 9.1, "recur": "weekly", "estimate": "1h"},
+	{"uuid": "b", "description": "Learn the cello", "status": "pending", "tags": ["someday_maybe"], "entry": "20260101T090000Z"},
+	{"uuid": "c", "description": "File taxes", "project": "Admin", "status": "completed", "entry": "20260101T090000Z", "end": "20260105T090000Z"},
+	{"uuid": "d", "description": "Hidden until March", "project": "Admin", "status": "waiting", "tags": ["waiting_for"], "priority": "L", "entry": "20260101T090000Z"},
+	{"uuid": "e", "description": "Weekly review", "status": "recurring", "entry": "20260101T090000Z"}
+]`
+
+func TestFetchTasks(t *testing.T) {
+	adapter, _ := newTestAdapter(t, exported)
+	tasks, err := adapter.FetchTasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 3 {
+		t.Fatalf("got %d tasks, want the 3 pending ones", len(tasks))
+	}
+
+	tests := []struct {
+		task     adapters.Task
+		project  string
+		inbox    bool
+		status   adapters.Status
+		priority adapters.Priority
+	}{
+		{tasks[0], "Home", false, adapters.StatusNext, adapters.PriorityCritical},
+		{tasks[1], "Inbox", true, adapters.StatusSomeday, adapters.PriorityLow},
+		{tasks[2], "Admin", false, adapters.StatusWaiting, adapters.PriorityMedium},
+	}
+	for _, tt := range tests {
+		if tt.task.Project != tt.project || tt.task.Inbox != tt.inbox || tt.task.Status != tt.status || tt.task.Priority != tt.priority {
+			t.Errorf("%s: got %s (inbox %v), %v, %v, want %s (inbox %v), %v, %v", tt.task.Content,
+				tt.task.Project, tt.task.Inbox, tt.task.Status, tt.task.Priority, tt.project, tt.inbox, tt.status, tt.priority)
+		}
+	}
+
+	task := tasks[0]
+	if task.ID != "a" || task.TaskManger != "taskwarrior" {
+		t.Errorf("got task %s of %s", task.ID, task.TaskManger)
+	}
+	if want := time.Date(2026, 1, 3, 9, 0, 0, 0, time.UTC); !task.UpdatedDate.Equal(want) {
+		t.Errorf("got updated %s, want the annotation's %s", task.UpdatedDate, want)
+	}
+	if task.DueDate == nil || !task.DueDate.Equal(time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("got due %v", task.DueDate)
+	}
+	if !slices.Equal(task.Notes, []string{"left a message"}) {
+		t.Errorf("got notes %v", task.Notes)
+	}
+}
+
+func TestFetchProjectsAndCompletedTasks(t *testing.T) {
+	adapter, _ := newTestAdapter(t, exported)
+	projects, err := adapter.FetchProjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 2 || projects[0].Name != "Admin" || projects[1].Name != "Home" {
+		t.Errorf("got projects %+v, want Admin and Home", projects)
+	}
+
+	completed, err := adapter.FetchCompletedTasks(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(completed) != 1 || completed[0].ID != "c" || completed[0].Status != adapters.StatusCompleted {
+		t.Errorf("got completed %+v, want File taxes", completed)
+	}
+}
+
+func TestEditTasks(t *testing.T) {
+	adapter, fake := newTestAdapter(t, exported)
+	tasks, err := adapter.FetchTasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	someday, content, project, note, due := adapters.StatusSomeday, "Call the electrician", "Admin", "done", "2026-02-01"
+	edits := []adapters.TaskEdit{
+		{Task: &tasks[0], Status: &someday, Content: &content, ProjectID: &project, AddTags: []string{"urgent"}, RemoveTags: []string{"phone"}, Due: &due},
+		{Task: &tasks[0], Note: &note, Subtasks: []string{"Find a number"}},
+		{Task: &tasks[1], Delete: true},
+		{Task: &tasks[2], Complete: true},
+	}
+	if err := adapter.EditTasks(&edits); err != nil {
+		t.Fatal(err)
+	}
+	if fake.imports != 1 {
+		t.Errorf("got %d imports, want 1", fake.imports)
+	}
+
+	items, err := adapter.export()
+	if err != nil {
+		t.Fatal(err)
+	}
+	byUUID := map[string]*Item{}
+	for i := range items {
+		byUUID[items[i].UUID] = &items[i]
+	}
+
+	edited := byUUID["a"].ToTask()
+	if edited.Content != content || edited.Project != project || edited.Status != adapters.StatusSomeday {
+		t.Errorf("got %q in %s, %v", edited.Content, edited.Project, edited.Status)
+	}
+	if !slices.Equal(edited.Tags, []string{"urgent", "someday_maybe"}) {
+		t.Errorf("got tags %v", edited.Tags)
+	}
+	if edited.DueDate == nil || edited.DueDate.Format("2006-01-02") != due {
+		t.Errorf("got due %v, want %s", edited.DueDate, due)
+	}
+	if !slices.Equal(edited.Notes, []string{"left a message", "done"}) {
+		t.Errorf("got notes %v", edited.Notes)
+	}
+	if string(byUUID["a"].raw["recur"]) != `"weekly"` || string(byUUID["a"].raw["estimate"]) != `"1h"` {
+		t.Errorf("lost attributes gitd does not know: %v", byUUID["a"].raw)
+	}
+
+	var depends []string
+	json.Unmarshal(byUUID["a"].raw["depends"], &depends)
+	if len(depends) != 1 || byUUID[depends[0]].Description != "Find a number" || byUUID[depends[0]].Project != project {
+		t.Errorf("got depends %v, want the new subtask", depends)
+	}
+	if byUUID["b"].Status != "deleted" || byUUID["b"].End == "" {
+		t.Errorf("got %s task, want deleted", byUUID["b"].Status)
+	}
+	if byUUID["d"].Status != "completed" || byUUID["d"].End == "" {
+		t.Errorf("got %s task, want completed", byUUID["d"].Status)
+	}
+}
+
+func TestEditTasksMissing(t *testing.T) {
+	adapter, fake := newTestAdapter(t, exported)
+	edits := []adapters.TaskEdit{{Task: &adapters.Task{ID: "c"}, Complete: true}}
+	if err := adapter.EditTasks(&edits); adapters.KindOf(err) != adapters.ErrorValidation {
+		t.Errorf("got error %v, want a validation error", err)
+	}
+	if fake.imports != 0 {
+		t.Error("imported tasks despite the error")
+	}
+}
+
+func TestCreateTasks(t *testing.T) {
+	adapter, _ := newTestAdapter(t, `[]`)
+	due := "2026-03-01"
+	drafts := []adapters.TaskDraft{
+		{Content: "one", ProjectID: "Home", Tags: []string{"next"}, Priority: adapters.PriorityHigh, Due: &due, Description: "details"},
+		{Content: "two"},
+	}
+	if err := adapter.CreateTasks(&drafts); err != nil {
+		t.Fatal(err)
+	}
+	tasks, err := adapter.FetchTasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("got %d tasks, want 2", len(tasks))
+	}
+	first := tasks[0]
+	if first.Content != "one" || first.Project != "Home" || first.Status != adapters.StatusNext || first.Priority != adapters.PriorityHigh || first.Description != "details" {
+		t.Errorf("got %+v", first)
+	}
+	if first.DueDate == nil || first.DueDate.Format("2006-01-02") != due {
+		t.Errorf("got due %v, want %s", first.DueDate, due)
+	}
+	if second := tasks[1]; second.Content != "two" || !second.Inbox || second.Priority != adapters.PriorityLow {
+		t.Errorf("got %+v", second)
+	}
+}
+
+func TestPriorityMapping(t *testing.T) {
+	for _, priority := range []adapters.Priority{adapters.PriorityCritical, adapters.PriorityHigh, adapters.PriorityMedium, adapters.PriorityLow} {
+		if got := toPriority(fromPriority(priority)); got != priority {
+			t.Errorf("%v became %v", priority, got)
+		}
+	}
+}
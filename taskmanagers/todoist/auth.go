@@ -29,11 +29,14 @@ var (
 func GenerateAccessToken(todoistConfig adapters.TodoistConfig) (string, error) {
 	switch todoistConfig.AuthType {
 	case adapters.AuthTypeToken:
+		if todoistConfig.AuthToken == nil || *todoistConfig.AuthToken == "" {
+			return "", adapters.Errorf(adapters.ErrorConfig, "todoist token is not set in the config file")
+		}
 		return *todoistConfig.AuthToken, nil
 	case adapters.AuthTypeOAuth2:
 		return PerformOAuthFlow(todoistConfig)
 	default:
-		return "", fmt.Errorf("unknown auth type: %s", todoistConfig.AuthType)
+		return "", adapters.Errorf(adapters.ErrorConfig, "unknown auth type: %s", todoistConfig.AuthType)
 	}
 }
 
@@ -1,11 +1,15 @@
 package todoist
 
 import (
+	"encoding/json"
 	"github.com/dormunis/gitd/adapters"
 	"time"
 )
 
-func (t *TodoistSyncResponse) ToTasks() []adapters.Task {
+const defaultWaitingLabel = "waiting_for"
+
+// ToTasks converts the items, reading floating due dates in location.
+func (t *TodoistSyncResponse) ToTasks(config adapters.TodoistConfig, location *time.Location) []adapters.Task {
 	var tasks []adapters.Task
 	for _, item := range *t.Items {
 		updatedDate := getLastNoteDateFromItem(*item.ID, t.Notes)
@@ -15,21 +19,59 @@ func (t *TodoistSyncResponse) ToTasks() []adapters.Task {
 			updatedDate = item.AddedAt
 		}
 
+		description := ""
+		if item.Description != nil {
+			description = *item.Description
+		}
+
 		tasks = append(tasks, adapters.Task{
 			ID:          *item.ID,
+			ProjectID:   *item.ProjectID,
 			Project:     t.getProjectName(*item.ProjectID),
+			Inbox:       t.isInboxProject(*item.ProjectID),
+			Section:     t.getSectionName(item.SectionID),
 			Content:     *item.Content,
+			Description: description,
+			Notes:       getNotesFromItem(*item.ID, t.Notes),
 			CreatedDate: *item.AddedAt,
 			UpdatedDate: *updatedDate,
+			DueDate:     item.Due.Time(location),
+			Duration:    item.Duration.Duration(),
 			Tags:        *item.Labels,
 			TaskManger:  "todoist",
-			Status:      deriveStatus(item),
-			Priority:    adapters.Priority(*item.Priority),
+			Status:      deriveStatus(item, waitingLabel(config)),
+			Priority:    toPriority(*item.Priority),
+			Energy:      deriveEnergy(item),
+			Waiting:     adapters.ParseWaitingInfo(description),
 		})
 	}
 	return tasks
 }
 
+func (t *TodoistCompletedResponse) ToTasks() []adapters.Task {
+	var tasks []adapters.Task
+	for _, item := range t.Items {
+		task := adapters.Task{
+			ID:          *item.TaskID,
+			ProjectID:   *item.ProjectID,
+			Project:     "<Unknown>",
+			Content:     *item.Content,
+			CompletedAt: item.CompletedAt,
+			Status:      adapters.StatusCompleted,
+			TaskManger:  "todoist",
+		}
+		if project, ok := t.Projects[*item.ProjectID]; ok && project.Name != nil {
+			task.Project = *project.Name
+			task.Inbox = project.InboxProject != nil && *project.InboxProject
+		}
+		if item.CompletedAt != nil {
+			task.UpdatedDate = *item.CompletedAt
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
 func (t *TodoistSyncResponse) getProjectName(projectID string) string {
 	for _, project := range *t.Projects {
 		if *project.ID == projectID {
@@ -39,6 +81,40 @@ func (t *TodoistSyncResponse) getProjectName(projectID string) string {
 	return "<Unknown>"
 }
 
+func (t *TodoistSyncResponse) getSectionName(sectionID *string) string {
+	if sectionID == nil || t.Sections == nil {
+		return ""
+	}
+	for _, section := range *t.Sections {
+		if *section.ID == *sectionID && section.Name != nil {
+			return *section.Name
+		}
+	}
+	return ""
+}
+
+func (t *TodoistSyncResponse) isInboxProject(projectID string) bool {
+	for _, project := range *t.Projects {
+		if *project.ID == projectID {
+			return project.InboxProject != nil && *project.InboxProject
+		}
+	}
+	return false
+}
+
+func getNotesFromItem(itemID string, notes *[]Note) []string {
+	if notes == nil {
+		return nil
+	}
+	var contents []string
+	for _, note := range *notes {
+		if *note.ItemID == itemID && note.Content != nil {
+			contents = append(contents, *note.Content)
+		}
+	}
+	return contents
+}
+
 func getLastNoteDateFromItem(itemID string, notes *[]Note) *time.Time {
 	var latest *time.Time
 	for _, note := range *notes {
@@ -52,10 +128,22 @@ func getLastNoteDateFromItem(itemID string, notes *[]Note) *time.Time {
 	return nil
 }
 
-func deriveStatus(item Item) adapters.Status {
+func waitingLabel(config adapters.TodoistConfig) string {
+	if config.WaitingLabel != "" {
+		return config.WaitingLabel
+	}
+	return defaultWaitingLabel
+}
+
+func deriveStatus(item Item, waitingLabel string) adapters.Status {
 	if item.CompletedAt != nil {
 		return adapters.StatusCompleted
 	}
+	for _, label := range *item.Labels {
+		if label == waitingLabel {
+			return adapters.StatusWaiting
+		}
+	}
 	// TODO: make these labels configurable in TodoistConfig or something
 	for _, label := range *item.Labels {
 		if label == "next" {
@@ -68,6 +156,31 @@ func deriveStatus(item Item) adapters.Status {
 	return adapters.StatusActive
 }
 
+// toPriority converts Todoist's priority, where 4 is the most urgent, into
+// adapters.Priority, where 1 is.
+func toPriority(priority int) adapters.Priority {
+	return adapters.Priority(5 - priority)
+}
+
+func fromPriority(priority adapters.Priority) int {
+	return 5 - int(priority)
+}
+
+func deriveEnergy(item Item) adapters.Energy {
+	// TODO: make these labels configurable as well
+	for _, label := range *item.Labels {
+		switch label {
+		case "low_energy":
+			return adapters.EnergyLow
+		case "medium_energy":
+			return adapters.EnergyMedium
+		case "high_energy":
+			return adapters.EnergyHigh
+		}
+	}
+	return adapters.EnergyUnknown
+}
+
 type TodoistSyncRequest struct {
 	SyncToken     string   `json:"sync_token"`
 	ResourceTypes []string `json:"resource_types"`
@@ -82,11 +195,26 @@ type TodoistSyncResponse struct {
 	User     *User      `json:"user"`
 }
 
+type TodoistCompletedResponse struct {
+	Items    []CompletedItem    `json:"items"`
+	Projects map[string]Project `json:"projects"`
+}
+
+type CompletedItem struct {
+	CompletedAt *time.Time `json:"completed_at"`
+	Content     *string    `json:"content"`
+	ID          *string    `json:"id"`
+	ProjectID   *string    `json:"project_id"`
+	TaskID      *string    `json:"task_id"`
+}
+
 type Item struct {
 	AddedAt     *time.Time `json:"added_at"`
 	CompletedAt *time.Time `json:"completed_at"`
 	Content     *string    `json:"content"`
 	Description *string    `json:"description"`
+	Due         *Due       `json:"due"`
+	Duration    *Duration  `json:"duration"`
 	ID          *string    `json:"id"`
 	Labels      *[]string  `json:"labels"`
 	ParentID    *string    `json:"parent_id"`
@@ -110,10 +238,13 @@ type Note struct {
 }
 
 type Project struct {
-	CreatedAt *time.Time `json:"created_at"`
-	ID        *string    `json:"id"`
-	Name      *string    `json:"name"`
-	UpdatedAt *time.Time `json:"updated_at"`
+	CreatedAt    *time.Time `json:"created_at"`
+	ID           *string    `json:"id"`
+	InboxProject *bool      `json:"inbox_project"`
+	IsArchived   *bool      `json:"is_archived"`
+	Name         *string    `json:"name"`
+	ParentID     *string    `json:"parent_id"`
+	UpdatedAt    *time.Time `json:"updated_at"`
 }
 
 type Section struct {
@@ -150,6 +281,22 @@ type TzInfo struct {
 	Timezone  *string `json:"timezone"`
 }
 
+func (tz *TzInfo) Location() *time.Location {
+	if tz.Timezone != nil {
+		if location, err := time.LoadLocation(*tz.Timezone); err == nil {
+			return location
+		}
+	}
+	if tz.Hours != nil && tz.Minutes != nil {
+		name := ""
+		if tz.GmtString != nil {
+			name = *tz.GmtString
+		}
+		return time.FixedZone(name, (*tz.Hours*60+*tz.Minutes)*60)
+	}
+	return time.Local
+}
+
 type Due struct {
 	Date        *string    `json:"date"`
 	IsRecurring *bool      `json:"is_recurring"`
@@ -158,11 +305,42 @@ type Due struct {
 	Timezone    *string    `json:"timezone"`
 }
 
+// Time returns the due date, treating floating dates (those without a
+// timezone) as times in location, the user's timezone.
+func (d *Due) Time(location *time.Location) *time.Time {
+	if d == nil {
+		return nil
+	}
+	if d.Datetime != nil {
+		return d.Datetime
+	}
+	if d.Date == nil {
+		return nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if due, err := time.ParseInLocation(layout, *d.Date, location); err == nil {
+			return &due
+		}
+	}
+	return nil
+}
+
 type Duration struct {
 	Amount *int    `json:"amount"`
 	Unit   *string `json:"unit"`
 }
 
+func (d *Duration) Duration() *time.Duration {
+	if d == nil || d.Amount == nil || d.Unit == nil {
+		return nil
+	}
+	duration := time.Duration(*d.Amount) * time.Minute
+	if *d.Unit == "day" {
+		duration = time.Duration(*d.Amount) * 24 * time.Hour
+	}
+	return &duration
+}
+
 type SyncResponseItem struct {
 	Type   string            `json:"type"`
 	Uuid   string            `json:"uuid"`
@@ -171,9 +349,26 @@ type SyncResponseItem struct {
 }
 
 type SyncResponseArgs struct {
-	Id      *string   `json:"id,omitempty"`
-	ItemId  *string   `json:"item_id,omitempty"`
-	Ids     *[]string `json:"ids,omitempty"`
-	Content *string   `json:"content,omitempty"`
-	Labels  *[]string `json:"labels,omitempty"`
+	Id          *string   `json:"id,omitempty"`
+	ItemId      *string   `json:"item_id,omitempty"`
+	Ids         *[]string `json:"ids,omitempty"`
+	Content     *string   `json:"content,omitempty"`
+	Name        *string   `json:"name,omitempty"`
+	Description *string   `json:"description,omitempty"`
+	Labels      *[]string `json:"labels,omitempty"`
+	Priority    *int      `json:"priority,omitempty"`
+	ProjectId   *string   `json:"project_id,omitempty"`
+	ParentId    *string   `json:"parent_id,omitempty"`
+	Due         *DueArgs  `json:"due,omitempty"`
+}
+
+type DueArgs struct {
+	String *string `json:"string,omitempty"`
+}
+
+// TodoistCommitResponse is the reply to a batch of commands. Each command's
+// status is either "ok" or an error object, hence kept raw.
+type TodoistCommitResponse struct {
+	SyncStatus    map[string]json.RawMessage `json:"sync_status"`
+	TempIdMapping map[string]string          `json:"temp_id_mapping"`
 }
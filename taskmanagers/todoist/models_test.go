@@ -0,0 +1,46 @@
+package todoist
+
+import (
+	"github.com/dormunis/gitd/adapters"
+	"testing"
+	"time"
+)
+
+func TestPriorityMapping(t *testing.T) {
+	tests := []struct {
+		todoist int
+		want    adapters.Priority
+	}{
+		{4, adapters.PriorityCritical},
+		{3, adapters.PriorityHigh},
+		{2, adapters.PriorityMedium},
+		{1, adapters.PriorityLow},
+	}
+	for _, tt := range tests {
+		if got := toPriority(tt.todoist); got != tt.want {
+			t.Errorf("toPriority(%d) = %v, want %v", tt.todoist, got, tt.want)
+		}
+		if got := fromPriority(tt.want); got != tt.todoist {
+			t.Errorf("fromPriority(%v) = %d, want %d", tt.want, got, tt.todoist)
+		}
+	}
+}
+
+func TestDueTimeFloatsInLocation(t *testing.T) {
+	location := time.FixedZone("UTC+10", 10*60*60)
+	tests := []struct {
+		date string
+		want time.Time
+	}{
+		{"2026-01-10", time.Date(2026, 1, 10, 0, 0, 0, 0, location)},
+		{"2026-01-10T09:30:00", time.Date(2026, 1, 10, 9, 30, 0, 0, location)},
+		{"2026-01-10T09:30:00Z", time.Date(2026, 1, 10, 9, 30, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		due := Due{Date: &tt.date}
+		got := due.Time(location)
+		if got == nil || !got.Equal(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.date, got, tt.want)
+		}
+	}
+}
@@ -2,26 +2,36 @@ package todoist
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"github.com/dormunis/gitd/adapters"
 	"net/http"
 	"net/url"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const (
+	completedPageSize = 200
+	revalidationNote  = "Revalidated on "
+)
+
 type TodoistAdapter struct {
-	endpointURL string
-	httpClient  *http.Client
-	authToken   string
-	settings    adapters.Settings
+	endpointURL  string
+	completedURL string
+	httpClient   *http.Client
+	authToken    string
+	settings     adapters.Settings
+	location     *time.Location
 }
 
 func (t *TodoistAdapter) Initialize(settings adapters.Settings) error {
 	t.endpointURL = "https://api.todoist.com/sync/v9/sync"
+	t.completedURL = "https://api.todoist.com/sync/v9/completed/get_all"
 	t.httpClient = &http.Client{
 		Timeout: 15 * time.Second, // Todoist default timeout
 	}
@@ -29,7 +39,7 @@ func (t *TodoistAdapter) Initialize(settings adapters.Settings) error {
 
 	authToken, err := GenerateAccessToken(settings.Todoist)
 	if err != nil {
-		return err
+		return adapters.NewError(adapters.ErrorAuth, err)
 	}
 	t.authToken = authToken
 
@@ -37,9 +47,87 @@ func (t *TodoistAdapter) Initialize(settings adapters.Settings) error {
 }
 
 func (t *TodoistAdapter) FetchTasks() ([]adapters.Task, error) {
+	result, err := t.fetch([]string{"items", "projects", "notes", "labels", "sections", "user"})
+	if err != nil {
+		return nil, err
+	}
+	if t.location == nil {
+		t.location = time.Local
+		if result.User != nil && result.User.TzInfo != nil {
+			t.location = result.User.TzInfo.Location()
+		}
+	}
+
+	tasks := result.ToTasks(t.settings.Todoist, t.location)
+	return tasks, nil
+}
+
+// Location returns the timezone configured in the user's Todoist settings,
+// falling back to the local timezone when it is unknown.
+func (t *TodoistAdapter) Location() (*time.Location, error) {
+	if t.location != nil {
+		return t.location, nil
+	}
+
+	result, err := t.fetch([]string{"user"})
+	if err != nil {
+		return nil, err
+	}
+	t.location = time.Local
+	if result.User != nil && result.User.TzInfo != nil {
+		t.location = result.User.TzInfo.Location()
+	}
+	return t.location, nil
+}
+
+// FetchCompletedTasks returns the tasks completed between since and until
+// from Todoist's completed archive.
+func (t *TodoistAdapter) FetchCompletedTasks(since time.Time, until time.Time) ([]adapters.Task, error) {
+	var tasks []adapters.Task
+	for offset := 0; ; offset += completedPageSize {
+		data := url.Values{}
+		data.Set("since", since.UTC().Format("2006-01-02T15:04"))
+		data.Set("until", until.UTC().Format("2006-01-02T15:04"))
+		data.Set("limit", strconv.Itoa(completedPageSize))
+		data.Set("offset", strconv.Itoa(offset))
+		req, err := http.NewRequest(http.MethodPost, t.completedURL, strings.NewReader(data.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+t.authToken)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		res, err := t.httpClient.Do(req)
+		if err != nil {
+			return nil, adapters.NewError(adapters.ErrorNetwork, err)
+		}
+
+		var result TodoistCompletedResponse
+		err = json.NewDecoder(res.Body).Decode(&result)
+		res.Body.Close()
+		if res.StatusCode < 200 || res.StatusCode >= 300 {
+			return nil, statusError(res)
+		}
+		if err != nil {
+			return nil, adapters.Errorf(adapters.ErrorAPI, "error decoding response: %w", err)
+		}
+
+		page := result.ToTasks()
+		tasks = append(tasks, page...)
+		if len(page) < completedPageSize {
+			return tasks, nil
+		}
+	}
+}
+
+func (t *TodoistAdapter) fetch(resourceTypes []string) (*TodoistSyncResponse, error) {
+	resourceTypesJSON, err := json.Marshal(resourceTypes)
+	if err != nil {
+		return nil, err
+	}
 	data := url.Values{}
 	data.Set("sync_token", "*")
-	data.Set("resource_types", "[\"items\",\"projects\",\"notes\",\"labels\",\"sections\"]")
+	data.Set("resource_types", string(resourceTypesJSON))
 	req, err := http.NewRequest(http.MethodPost, t.endpointURL, strings.NewReader(data.Encode()))
 	if err != nil {
 		return nil, err
@@ -50,27 +138,39 @@ func (t *TodoistAdapter) FetchTasks() ([]adapters.Task, error) {
 	res, err := t.httpClient.Do(req)
 
 	if err != nil {
-		return nil, err
+		return nil, adapters.NewError(adapters.ErrorNetwork, err)
 	}
 	defer res.Body.Close()
 
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, statusError(res)
+	}
+
 	var result TodoistSyncResponse
 	decoder := json.NewDecoder(res.Body)
 
 	if err := decoder.Decode(&result); err != nil {
-		fmt.Println("error decoding response", err)
-		return nil, err
+		return nil, adapters.Errorf(adapters.ErrorAPI, "error decoding response: %w", err)
 	}
 
-	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		return nil, fmt.Errorf("Status code error: %d for url: %s", res.StatusCode, req.URL)
-	}
+	return &result, nil
+}
 
-	tasks := result.ToTasks()
-	return tasks, nil
+// statusError describes a failed response: rejected credentials are auth
+// errors, anything else is an API error.
+func statusError(res *http.Response) error {
+	err := fmt.Errorf("Status code error: %d for url: %s", res.StatusCode, res.Request.URL)
+	if res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden {
+		return adapters.NewError(adapters.ErrorAuth, err)
+	}
+	return adapters.NewError(adapters.ErrorAPI, err)
 }
 
 func (t *TodoistAdapter) UpdateTasks(actions *[]adapters.TaskAction) error {
+	return t.commit(prepareUpdateSync(actions))
+}
+
+func prepareUpdateSync(actions *[]adapters.TaskAction) []SyncResponseItem {
 	syncResponse := []SyncResponseItem{}
 
 	prepareCompletedSync(actions, &syncResponse)
@@ -78,50 +178,391 @@ func (t *TodoistAdapter) UpdateTasks(actions *[]adapters.TaskAction) error {
 	prepareDeferredSync(actions, &syncResponse)
 	prepareRevalidateSync(actions, &syncResponse)
 
-	jsonBytes, err := json.MarshalIndent(syncResponse, "", "  ")
-	if err != nil {
-		log.Fatalf("JSON marshaling failed: %s", err)
+	return syncResponse
+}
+
+// PlanTasks prepares the sync commands for actions without sending them.
+// Todoist ignores commands whose uuid it has already seen, so applying the
+// same plan twice is harmless.
+func (t *TodoistAdapter) PlanTasks(actions *[]adapters.TaskAction) (*adapters.Plan, error) {
+	plan := &adapters.Plan{TaskManager: "todoist", CreatedAt: time.Now()}
+	for _, action := range *actions {
+		commands := prepareUpdateSync(&[]adapters.TaskAction{action})
+		if len(commands) == 0 {
+			continue
+		}
+
+		step := adapters.NewPlanStep(action.Task, action.Action)
+		for _, command := range commands {
+			commandJSON, err := json.Marshal(command)
+			if err != nil {
+				return nil, err
+			}
+			step.Commands = append(step.Commands, commandJSON)
+		}
+		plan.Steps = append(plan.Steps, step)
+	}
+	return plan, nil
+}
+
+func (t *TodoistAdapter) ApplyPlan(plan *adapters.Plan) error {
+	if plan.TaskManager != "todoist" {
+		return adapters.Errorf(adapters.ErrorValidation, "plan was made for %s, not todoist", plan.TaskManager)
 	}
 
-	fmt.Println(string(jsonBytes))
+	// Deferring replaces the labels, which are checked against the task's own.
+	labels := make(map[string][]string)
+	if slices.ContainsFunc(plan.Steps, func(step adapters.PlanStep) bool { return step.Action == adapters.ActionDefer }) {
+		result, err := t.fetch([]string{"items"})
+		if err != nil {
+			return err
+		}
+		if result.Items != nil {
+			for _, item := range *result.Items {
+				if item.ID != nil && item.Labels != nil {
+					labels[*item.ID] = *item.Labels
+				}
+			}
+		}
+	}
+
+	var commands []SyncResponseItem
+	for _, step := range plan.Steps {
+		for _, commandJSON := range step.Commands {
+			var command SyncResponseItem
+			if err := json.Unmarshal(commandJSON, &command); err != nil {
+				return adapters.Errorf(adapters.ErrorValidation, "invalid command for task %s: %w", step.TaskID, err)
+			}
+			if err := checkPlanCommand(&step, &command, labels[step.TaskID]); err != nil {
+				return adapters.Errorf(adapters.ErrorValidation, "invalid command for task %s: %w", step.TaskID, err)
+			}
+			commands = append(commands, command)
+		}
+	}
+	return t.commit(commands)
+}
+
+// planCommandTypes are the commands PlanTasks makes for each action.
+var planCommandTypes = map[adapters.Action]string{
+	adapters.ActionComplete:   "item_complete",
+	adapters.ActionDelete:     "item_delete",
+	adapters.ActionDefer:      "item_update",
+	adapters.ActionRevalidate: "note_add",
+}
+
+// checkPlanCommand makes sure a command read from a plan file is one
+// PlanTasks could have made for its step, so that an edited plan cannot
+// touch other tasks or make other changes than its actions describe. labels
+// are the task's current labels.
+func checkPlanCommand(step *adapters.PlanStep, command *SyncResponseItem, labels []string) error {
+	if want, ok := planCommandTypes[step.Action]; !ok || command.Type != want {
+		return fmt.Errorf("%s command does not %s the task", command.Type, step.Action)
+	}
+	if command.Uuid == "" || command.TempId != nil || command.Args == nil {
+		return fmt.Errorf("malformed %s command", command.Type)
+	}
+
+	args := *command.Args
+	id := args.Id
+	if command.Type == "note_add" {
+		id, args.ItemId = args.ItemId, nil
+		if args.Content == nil || !strings.HasPrefix(*args.Content, revalidationNote) {
+			return fmt.Errorf("note_add command does not add a revalidation note")
+		}
+		args.Content = nil
+	} else {
+		args.Id = nil
+	}
+	if command.Type == "item_update" {
+		if args.Labels == nil || !sameLabels(*args.Labels, deferLabels(labels)) {
+			return fmt.Errorf("item_update command sets other labels than deferring the task")
+		}
+		args.Labels = nil
+	}
+	if id == nil || *id != step.TaskID {
+		return fmt.Errorf("%s command is for another task", command.Type)
+	}
+	if args != (SyncResponseArgs{}) {
+		return fmt.Errorf("%s command changes more than its action", command.Type)
+	}
 	return nil
 }
 
+func sameLabels(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
+
+func (t *TodoistAdapter) FetchProjects() ([]adapters.Project, error) {
+	result, err := t.fetch([]string{"projects", "sections"})
+	if err != nil {
+		return nil, err
+	}
+
+	var projects []adapters.Project
+	for _, project := range *result.Projects {
+		converted := adapters.Project{
+			ID:          *project.ID,
+			Name:        *project.Name,
+			Inbox:       project.InboxProject != nil && *project.InboxProject,
+			Archived:    project.IsArchived != nil && *project.IsArchived,
+			CreatedDate: project.CreatedAt,
+		}
+		if project.ParentID != nil {
+			converted.ParentID = *project.ParentID
+		}
+		if result.Sections != nil {
+			for _, section := range *result.Sections {
+				if *section.ProjectID == *project.ID {
+					converted.Sections = append(converted.Sections, *section.Name)
+				}
+			}
+		}
+		projects = append(projects, converted)
+	}
+	return projects, nil
+}
+
+func (t *TodoistAdapter) ArchiveProjects(projects *[]adapters.Project) error {
+	syncResponse := []SyncResponseItem{}
+	for i := range *projects {
+		syncResponse = append(syncResponse, SyncResponseItem{
+			Type: "project_archive",
+			Uuid: uuid.New().String(),
+			Args: &SyncResponseArgs{Id: &(*projects)[i].ID},
+		})
+	}
+	return t.commit(syncResponse)
+}
+
+// EditTasks sends all edits to Todoist as a single sync batch.
+func (t *TodoistAdapter) EditTasks(edits *[]adapters.TaskEdit) error {
+	syncResponse := []SyncResponseItem{}
+	statusLabels := t.statusLabels()
+	for i := range *edits {
+		prepareEditSync(&(*edits)[i], statusLabels, &syncResponse)
+	}
+	return t.commit(syncResponse)
+}
+
+// CreateTasks adds all drafts in a single sync batch.
+func (t *TodoistAdapter) CreateTasks(drafts *[]adapters.TaskDraft) error {
+	syncResponse := []SyncResponseItem{}
+	for i := range *drafts {
+		prepareAddSync(&(*drafts)[i], &syncResponse)
+	}
+	return t.commit(syncResponse)
+}
+
+// CreateProjects adds top-level projects with the given names.
+func (t *TodoistAdapter) CreateProjects(names []string) ([]adapters.Project, error) {
+	syncResponse := []SyncResponseItem{}
+	for i := range names {
+		tempID := uuid.New().String()
+		syncResponse = append(syncResponse, SyncResponseItem{
+			Type:   "project_add",
+			Uuid:   uuid.New().String(),
+			TempId: &tempID,
+			Args:   &SyncResponseArgs{Name: &names[i]},
+		})
+	}
+	result, err := t.send(syncResponse)
+	if err != nil || result == nil {
+		return nil, err
+	}
+
+	projects := make([]adapters.Project, len(names))
+	for i, command := range syncResponse {
+		projects[i] = adapters.Project{ID: result.TempIdMapping[*command.TempId], Name: names[i]}
+	}
+	return projects, nil
+}
+
+func (t *TodoistAdapter) FetchLabels() ([]string, error) {
+	result, err := t.fetch([]string{"labels"})
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []string
+	if result.Labels != nil {
+		for _, label := range *result.Labels {
+			labels = append(labels, *label.Name)
+		}
+	}
+	return labels, nil
+}
+
+// CreateLabels adds personal labels with the given names.
+func (t *TodoistAdapter) CreateLabels(names []string) error {
+	syncResponse := []SyncResponseItem{}
+	for i := range names {
+		syncResponse = append(syncResponse, SyncResponseItem{
+			Type: "label_add",
+			Uuid: uuid.New().String(),
+			Args: &SyncResponseArgs{Name: &names[i]},
+		})
+	}
+	return t.commit(syncResponse)
+}
+
+// maxCommands is the most commands Todoist accepts in one sync request.
+const maxCommands = 100
+
+// commit sends the commands to the sync endpoint and fails if any of them was
+// rejected.
+func (t *TodoistAdapter) commit(commands []SyncResponseItem) error {
+	_, err := t.send(commands)
+	return err
+}
+
+// send is commit, also returning the replies so that the IDs given to created
+// resources can be read from them. Commands are sent in requests of at most
+// maxCommands, with the temporary IDs of earlier requests replaced by the IDs
+// they were given. When a request fails, the reply covers the ones before it.
+// The reply is nil when there is no command.
+func (t *TodoistAdapter) send(commands []SyncResponseItem) (*TodoistCommitResponse, error) {
+	if len(commands) == 0 {
+		return nil, nil
+	}
+
+	result := &TodoistCommitResponse{SyncStatus: map[string]json.RawMessage{}, TempIdMapping: map[string]string{}}
+	for start := 0; start < len(commands); start += maxCommands {
+		batch := commands[start:min(start+maxCommands, len(commands))]
+		for i := range batch {
+			resolveTempIDs(batch[i].Args, result.TempIdMapping)
+		}
+		reply, err := t.sendBatch(batch)
+		if err != nil {
+			if start > 0 {
+				err = fmt.Errorf("%w (after the first %d of %d commands were applied)", err, start, len(commands))
+			}
+			return result, failedTasks(err, commands, result)
+		}
+		for uuid, status := range reply.SyncStatus {
+			result.SyncStatus[uuid] = status
+		}
+		for tempID, id := range reply.TempIdMapping {
+			result.TempIdMapping[tempID] = id
+		}
+	}
+
+	var failures []string
+	for _, command := range commands {
+		status, ok := result.SyncStatus[command.Uuid]
+		if !ok || string(status) == `"ok"` {
+			continue
+		}
+		failures = append(failures, fmt.Sprintf("%s: %s", command.Type, status))
+	}
+	if len(failures) > 0 {
+		err := adapters.Errorf(adapters.ErrorAPI, "%d of %d commands failed:\n%s", len(failures), len(commands), strings.Join(failures, "\n"))
+		return result, failedTasks(err, commands, result)
+	}
+	return result, nil
+}
+
+// failedTasks wraps err with the tasks of the commands that were not
+// confirmed by a status of "ok".
+func failedTasks(err error, commands []SyncResponseItem, result *TodoistCommitResponse) error {
+	partial := &adapters.PartialError{Err: err}
+	for _, command := range commands {
+		if status, ok := result.SyncStatus[command.Uuid]; ok && string(status) == `"ok"` {
+			continue
+		}
+		if command.Args == nil {
+			continue
+		}
+		for _, id := range []*string{command.Args.Id, command.Args.ItemId} {
+			if id != nil {
+				partial.Failed = append(partial.Failed, *id)
+			}
+		}
+		if command.Args.Ids != nil {
+			partial.Failed = append(partial.Failed, *command.Args.Ids...)
+		}
+	}
+	return partial
+}
+
+func (t *TodoistAdapter) sendBatch(commands []SyncResponseItem) (*TodoistCommitResponse, error) {
+	commandsJSON, err := json.Marshal(commands)
+	if err != nil {
+		return nil, err
+	}
+	data := url.Values{}
+	data.Set("commands", string(commandsJSON))
+	req, err := http.NewRequest(http.MethodPost, t.endpointURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+t.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := t.httpClient.Do(req)
+	if err != nil {
+		return nil, adapters.NewError(adapters.ErrorNetwork, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, statusError(res)
+	}
+
+	var result TodoistCommitResponse
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, adapters.Errorf(adapters.ErrorAPI, "error decoding response: %w", err)
+	}
+	return &result, nil
+}
+
+// resolveTempIDs replaces temporary IDs given an ID by an earlier request.
+func resolveTempIDs(args *SyncResponseArgs, mapping map[string]string) {
+	if args == nil {
+		return
+	}
+	for _, field := range []**string{&args.Id, &args.ItemId, &args.ParentId, &args.ProjectId} {
+		if *field == nil {
+			continue
+		}
+		if id, ok := mapping[**field]; ok {
+			*field = &id
+		}
+	}
+}
+
 func prepareCompletedSync(actions *[]adapters.TaskAction, syncResponse *[]SyncResponseItem) {
 	tasksToComplete := getAllTasksWithAction(actions, adapters.ActionComplete)
-	if len(*tasksToComplete) == 0 {
-		return
+	for _, task := range *tasksToComplete {
+		*syncResponse = append(*syncResponse, SyncResponseItem{
+			Type: "item_complete",
+			Uuid: uuid.New().String(),
+			Args: &SyncResponseArgs{
+				Id: &task.Task.ID,
+			},
+		})
 	}
-	idsToComplete := extractIds(tasksToComplete)
-	*syncResponse = append(*syncResponse, SyncResponseItem{
-		Type: "item_complete",
-		Uuid: uuid.New().String(),
-		Args: &SyncResponseArgs{
-			Ids: idsToComplete,
-		},
-	})
 }
 
 func prepareDeletedSync(actions *[]adapters.TaskAction, syncResponse *[]SyncResponseItem) {
 	tasksToDelete := getAllTasksWithAction(actions, adapters.ActionDelete)
-	if len(*tasksToDelete) == 0 {
-		return
+	for _, task := range *tasksToDelete {
+		*syncResponse = append(*syncResponse, SyncResponseItem{
+			Type: "item_delete",
+			Uuid: uuid.New().String(),
+			Args: &SyncResponseArgs{
+				Id: &task.Task.ID,
+			},
+		})
 	}
-	idsToDelete := extractIds(tasksToDelete)
-	*syncResponse = append(*syncResponse, SyncResponseItem{
-		Type: "item_delete",
-		Uuid: uuid.New().String(),
-		Args: &SyncResponseArgs{
-			Ids: idsToDelete,
-		},
-	})
 }
 
 func prepareDeferredSync(actions *[]adapters.TaskAction, syncResponse *[]SyncResponseItem) {
 	tasksToDefer := getAllTasksWithAction(actions, adapters.ActionDefer)
 	for _, task := range *tasksToDefer {
-		// TODO: make these tags configurable
-		tags := updateLabels(task.Task.Tags, "someday_maybe", []string{"next"})
+		tags := deferLabels(task.Task.Tags)
 		item := SyncResponseItem{
 			Type: "item_update",
 			Uuid: uuid.New().String(),
@@ -136,7 +577,7 @@ func prepareDeferredSync(actions *[]adapters.TaskAction, syncResponse *[]SyncRes
 
 func prepareRevalidateSync(actions *[]adapters.TaskAction, syncResponse *[]SyncResponseItem) {
 	tasksToRevalidate := getAllTasksWithAction(actions, adapters.ActionRevalidate)
-	comment := "Revalidated on " + time.Now().Format("2006-01-02")
+	comment := revalidationNote + time.Now().Format("2006-01-02")
 	for _, task := range *tasksToRevalidate {
 		*syncResponse = append(*syncResponse, SyncResponseItem{
 			Type: "note_add",
@@ -149,6 +590,256 @@ func prepareRevalidateSync(actions *[]adapters.TaskAction, syncResponse *[]SyncR
 	}
 }
 
+// UndoActions reverses a journaled run: completed tasks are uncompleted,
+// deleted ones are recreated with their notes, deferred ones get their labels
+// back and revalidation notes are removed. Recreated tasks get new IDs and
+// lose their subtasks and recurrence. When only some of them are reversed,
+// the returned PartialError lists the tasks that were not.
+func (t *TodoistAdapter) UndoActions(run *adapters.JournalRun) error {
+	if run.TaskManager != "todoist" {
+		return adapters.Errorf(adapters.ErrorValidation, "run %s was applied to %s, not todoist", run.ID, run.TaskManager)
+	}
+	result, err := t.fetch([]string{"notes"})
+	if err != nil {
+		return err
+	}
+
+	syncResponse := []SyncResponseItem{}
+	owners := make(map[string]string) // command uuid to the task it reverses
+	for i := len(run.Entries) - 1; i >= 0; i-- {
+		task := &run.Entries[i].Task
+		first := len(syncResponse)
+		switch run.Entries[i].Action {
+		case adapters.ActionComplete:
+			syncResponse = append(syncResponse, SyncResponseItem{
+				Type: "item_uncomplete",
+				Uuid: uuid.New().String(),
+				Args: &SyncResponseArgs{Id: &task.ID},
+			})
+		case adapters.ActionDelete:
+			prepareRestoreSync(task, &syncResponse)
+		case adapters.ActionDefer:
+			labels := append([]string{}, task.Tags...)
+			syncResponse = append(syncResponse, SyncResponseItem{
+				Type: "item_update",
+				Uuid: uuid.New().String(),
+				Args: &SyncResponseArgs{Id: &task.ID, Labels: &labels},
+			})
+		case adapters.ActionRevalidate:
+			prepareRevalidationNoteDeletes(task.ID, run.AppliedAt, result.Notes, &syncResponse)
+		}
+		commands := syncResponse[first:]
+		// A recreated task must not be recreated again for its notes' sake.
+		if run.Entries[i].Action == adapters.ActionDelete {
+			commands = commands[:1]
+		}
+		for _, command := range commands {
+			owners[command.Uuid] = task.ID
+		}
+	}
+
+	reply, err := t.send(syncResponse)
+	var partial *adapters.PartialError
+	if errors.As(err, &partial) {
+		partial.Failed = nil
+		for _, command := range syncResponse {
+			owner, ok := owners[command.Uuid]
+			if status, sent := reply.SyncStatus[command.Uuid]; !ok || sent && string(status) == `"ok"` || slices.Contains(partial.Failed, owner) {
+				continue
+			}
+			partial.Failed = append(partial.Failed, owner)
+		}
+	}
+	return err
+}
+
+// prepareRestoreSync recreates a deleted task from its snapshot, notes
+// included.
+func prepareRestoreSync(task *adapters.Task, syncResponse *[]SyncResponseItem) {
+	tempID := uuid.New().String()
+	priority := fromPriority(task.Priority)
+	args := &SyncResponseArgs{
+		Content:   &task.Content,
+		ProjectId: &task.ProjectID,
+		Priority:  &priority,
+	}
+	if task.Description != "" {
+		args.Description = &task.Description
+	}
+	if len(task.Tags) > 0 {
+		args.Labels = &task.Tags
+	}
+	if task.DueDate != nil {
+		due := task.DueDate.Format("2006-01-02")
+		if task.DueDate.Hour() != 0 || task.DueDate.Minute() != 0 {
+			due = task.DueDate.Format("2006-01-02 15:04")
+		}
+		args.Due = &DueArgs{String: &due}
+	}
+	*syncResponse = append(*syncResponse, SyncResponseItem{
+		Type:   "item_add",
+		Uuid:   uuid.New().String(),
+		TempId: &tempID,
+		Args:   args,
+	})
+
+	for i := range task.Notes {
+		*syncResponse = append(*syncResponse, SyncResponseItem{
+			Type: "note_add",
+			Uuid: uuid.New().String(),
+			Args: &SyncResponseArgs{ItemId: &tempID, Content: &task.Notes[i]},
+		})
+	}
+}
+
+// prepareRevalidationNoteDeletes removes the revalidation notes added to the
+// task since appliedAt.
+func prepareRevalidationNoteDeletes(taskID string, appliedAt time.Time, notes *[]Note, syncResponse *[]SyncResponseItem) {
+	if notes == nil {
+		return
+	}
+	since := appliedAt.Add(-time.Minute)
+	for _, note := range *notes {
+		if *note.ItemID != taskID || note.Content == nil || !strings.HasPrefix(*note.Content, revalidationNote) {
+			continue
+		}
+		if note.PostedAt == nil || note.PostedAt.Before(since) {
+			continue
+		}
+		*syncResponse = append(*syncResponse, SyncResponseItem{
+			Type: "note_delete",
+			Uuid: uuid.New().String(),
+			Args: &SyncResponseArgs{Id: note.ID},
+		})
+	}
+}
+
+// statusLabels maps the statuses that Todoist represents as labels.
+func (t *TodoistAdapter) statusLabels() map[adapters.Status]string {
+	return map[adapters.Status]string{
+		adapters.StatusNext:    "next",
+		adapters.StatusSomeday: "someday_maybe",
+		adapters.StatusWaiting: waitingLabel(t.settings.Todoist),
+	}
+}
+
+func prepareEditSync(edit *adapters.TaskEdit, statusLabels map[adapters.Status]string, syncResponse *[]SyncResponseItem) {
+	id := edit.Task.ID
+	if edit.Delete {
+		*syncResponse = append(*syncResponse, SyncResponseItem{
+			Type: "item_delete",
+			Uuid: uuid.New().String(),
+			Args: &SyncResponseArgs{Id: &id},
+		})
+		return
+	}
+
+	projectID := edit.Task.ProjectID
+	if edit.ProjectID != nil && *edit.ProjectID != edit.Task.ProjectID {
+		projectID = *edit.ProjectID
+		*syncResponse = append(*syncResponse, SyncResponseItem{
+			Type: "item_move",
+			Uuid: uuid.New().String(),
+			Args: &SyncResponseArgs{Id: &id, ProjectId: &projectID},
+		})
+	}
+
+	addTags, removeTags := edit.AddTags, edit.RemoveTags
+	if edit.Status != nil {
+		for status, label := range statusLabels {
+			if status == *edit.Status {
+				addTags = append(slices.Clone(addTags), label)
+			} else {
+				removeTags = append(slices.Clone(removeTags), label)
+			}
+		}
+	}
+
+	if len(addTags) > 0 || len(removeTags) > 0 || edit.Due != nil || edit.Description != nil || edit.Content != nil || edit.Priority != nil {
+		args := &SyncResponseArgs{Id: &id, Content: edit.Content, Description: edit.Description}
+		if edit.Priority != nil {
+			priority := fromPriority(*edit.Priority)
+			args.Priority = &priority
+		}
+		if len(addTags) > 0 || len(removeTags) > 0 {
+			labels := changeLabels(edit.Task.Tags, addTags, removeTags)
+			args.Labels = &labels
+		}
+		if edit.Due != nil {
+			args.Due = &DueArgs{String: edit.Due}
+		}
+		*syncResponse = append(*syncResponse, SyncResponseItem{
+			Type: "item_update",
+			Uuid: uuid.New().String(),
+			Args: args,
+		})
+	}
+
+	for i := range edit.Subtasks {
+		tempID := uuid.New().String()
+		*syncResponse = append(*syncResponse, SyncResponseItem{
+			Type:   "item_add",
+			Uuid:   uuid.New().String(),
+			TempId: &tempID,
+			Args: &SyncResponseArgs{
+				Content:   &edit.Subtasks[i],
+				ProjectId: &projectID,
+				ParentId:  &id,
+			},
+		})
+	}
+
+	if edit.Note != nil {
+		*syncResponse = append(*syncResponse, SyncResponseItem{
+			Type: "note_add",
+			Uuid: uuid.New().String(),
+			Args: &SyncResponseArgs{ItemId: &id, Content: edit.Note},
+		})
+	}
+
+	if edit.Complete {
+		*syncResponse = append(*syncResponse, SyncResponseItem{
+			Type: "item_complete",
+			Uuid: uuid.New().String(),
+			Args: &SyncResponseArgs{Id: &id},
+		})
+	}
+}
+
+// prepareAddSync copies the draft's values, so that the commands do not
+// change with the draft once prepared.
+func prepareAddSync(draft *adapters.TaskDraft, syncResponse *[]SyncResponseItem) {
+	tempID := uuid.New().String()
+	content, description, projectID := draft.Content, draft.Description, draft.ProjectID
+	args := &SyncResponseArgs{
+		Content: &content,
+	}
+	if len(draft.Tags) > 0 {
+		labels := slices.Clone(draft.Tags)
+		args.Labels = &labels
+	}
+	if description != "" {
+		args.Description = &description
+	}
+	if projectID != "" {
+		args.ProjectId = &projectID
+	}
+	if draft.Priority != 0 {
+		priority := fromPriority(draft.Priority)
+		args.Priority = &priority
+	}
+	if draft.Due != nil {
+		due := *draft.Due
+		args.Due = &DueArgs{String: &due}
+	}
+	*syncResponse = append(*syncResponse, SyncResponseItem{
+		Type:   "item_add",
+		Uuid:   uuid.New().String(),
+		TempId: &tempID,
+		Args:   args,
+	})
+}
+
 func getAllTasksWithAction(actions *[]adapters.TaskAction, action adapters.Action) *[]adapters.TaskAction {
 	var tasks []adapters.TaskAction
 	for _, a := range *actions {
@@ -159,12 +850,10 @@ func getAllTasksWithAction(actions *[]adapters.TaskAction, action adapters.Actio
 	return &tasks
 }
 
-func extractIds(tasks *[]adapters.TaskAction) *[]string {
-	var ids []string
-	for _, task := range *tasks {
-		ids = append(ids, task.Task.ID)
-	}
-	return &ids
+// deferLabels returns the labels of a deferred task.
+// TODO: make these tags configurable
+func deferLabels(labels []string) []string {
+	return updateLabels(labels, "someday_maybe", []string{"next"})
 }
 
 func updateLabels(labels []string, labelToAdd string, labelsToRemove []string) []string {
@@ -191,6 +880,22 @@ func updateLabels(labels []string, labelToAdd string, labelsToRemove []string) [
 	return list
 }
 
+// changeLabels adds and removes labels while keeping the original order.
+func changeLabels(labels []string, labelsToAdd []string, labelsToRemove []string) []string {
+	changed := []string{}
+	for _, label := range labels {
+		if !slices.Contains(labelsToRemove, label) && !slices.Contains(changed, label) {
+			changed = append(changed, label)
+		}
+	}
+	for _, label := range labelsToAdd {
+		if !slices.Contains(labelsToRemove, label) && !slices.Contains(changed, label) {
+			changed = append(changed, label)
+		}
+	}
+	return changed
+}
+
 func NewTodoistAdapter() (adapters.TaskManagerAdapter, error) {
 	return &TodoistAdapter{}, nil
 }
@@ -0,0 +1,217 @@
+package todoist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dormunis/gitd/adapters"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// newTestAdapter points an adapter at a fake sync endpoint, which records the
+// commands of every request and accepts them all.
+// newTestAdapter returns an adapter whose reads return items and whose
+// commits are recorded in the returned requests.
+func newTestAdapter(t *testing.T, items ...Item) (*TodoistAdapter, *[][]SyncResponseItem) {
+	var requests [][]SyncResponseItem
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("resource_types") != "" {
+			json.NewEncoder(w).Encode(TodoistSyncResponse{Items: &items})
+			return
+		}
+		var commands []SyncResponseItem
+		if err := json.Unmarshal([]byte(r.FormValue("commands")), &commands); err != nil {
+			t.Errorf("invalid commands: %v", err)
+		}
+		requests = append(requests, commands)
+
+		result := TodoistCommitResponse{SyncStatus: map[string]json.RawMessage{}, TempIdMapping: map[string]string{}}
+		for _, command := range commands {
+			result.SyncStatus[command.Uuid] = json.RawMessage(`"ok"`)
+			if command.TempId != nil {
+				result.TempIdMapping[*command.TempId] = "id-" + *command.TempId
+			}
+		}
+		json.NewEncoder(w).Encode(result)
+	}))
+	t.Cleanup(server.Close)
+	return &TodoistAdapter{endpointURL: server.URL, httpClient: server.Client(), authToken: "token"}, &requests
+}
+
+func TestCreateTasksSendsEveryDraft(t *testing.T) {
+	adapter, requests := newTestAdapter(t)
+	due := "tomorrow"
+	drafts := []adapters.TaskDraft{
+		{Content: "one", ProjectID: "p1", Tags: []string{"a"}},
+		{Content: "two", ProjectID: "p2", Tags: []string{"b"}, Due: &due},
+		{Content: "three"},
+	}
+	if err := adapter.CreateTasks(&drafts); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*requests) != 1 || len((*requests)[0]) != len(drafts) {
+		t.Fatalf("got requests %+v, want one with %d commands", *requests, len(drafts))
+	}
+	for i, command := range (*requests)[0] {
+		draft := drafts[i]
+		if command.Type != "item_add" || *command.Args.Content != draft.Content {
+			t.Errorf("command %d: got %s %q, want item_add %q", i, command.Type, *command.Args.Content, draft.Content)
+		}
+		projectID := ""
+		if command.Args.ProjectId != nil {
+			projectID = *command.Args.ProjectId
+		}
+		if projectID != draft.ProjectID {
+			t.Errorf("command %d: got project %q, want %q", i, projectID, draft.ProjectID)
+		}
+		var labels []string
+		if command.Args.Labels != nil {
+			labels = *command.Args.Labels
+		}
+		if len(labels) != len(draft.Tags) || (len(labels) > 0 && labels[0] != draft.Tags[0]) {
+			t.Errorf("command %d: got labels %v, want %v", i, labels, draft.Tags)
+		}
+		if (command.Args.Due != nil) != (draft.Due != nil) {
+			t.Errorf("command %d: got due %+v, want %v", i, command.Args.Due, draft.Due)
+		}
+	}
+}
+
+func TestSendSplitsBatches(t *testing.T) {
+	adapter, requests := newTestAdapter(t)
+	drafts := make([]adapters.TaskDraft, 2*maxCommands+50)
+	for i := range drafts {
+		drafts[i].Content = fmt.Sprintf("task %d", i)
+	}
+	if err := adapter.CreateTasks(&drafts); err != nil {
+		t.Fatal(err)
+	}
+
+	var sizes []int
+	for _, commands := range *requests {
+		sizes = append(sizes, len(commands))
+	}
+	if !slices.Equal(sizes, []int{maxCommands, maxCommands, 50}) {
+		t.Errorf("got requests of %v commands, want %v", sizes, []int{maxCommands, maxCommands, 50})
+	}
+	if got := *(*requests)[2][49].Args.Content; got != "task 249" {
+		t.Errorf("got last command %q, want %q", got, "task 249")
+	}
+}
+
+func TestSendResolvesTempIDsOfEarlierBatches(t *testing.T) {
+	adapter, requests := newTestAdapter(t)
+	var commands []SyncResponseItem
+	for i := 0; i < maxCommands; i++ {
+		prepareAddSync(&adapters.TaskDraft{Content: fmt.Sprintf("task %d", i)}, &commands)
+	}
+	tempID := *commands[maxCommands-1].TempId
+	note := "note"
+	commands = append(commands, SyncResponseItem{Type: "note_add", Uuid: "note", Args: &SyncResponseArgs{ItemId: &tempID, Content: &note}})
+	if _, err := adapter.send(commands); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(*requests))
+	}
+	if got := *(*requests)[1][0].Args.ItemId; got != "id-"+tempID {
+		t.Errorf("got note for item %q, want %q", got, "id-"+tempID)
+	}
+}
+
+func TestSendReportsFailedTasks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var commands []SyncResponseItem
+		json.Unmarshal([]byte(r.FormValue("commands")), &commands)
+		result := TodoistCommitResponse{SyncStatus: map[string]json.RawMessage{}}
+		for _, command := range commands {
+			result.SyncStatus[command.Uuid] = json.RawMessage(`"ok"`)
+			if *command.Args.Id == "2" {
+				result.SyncStatus[command.Uuid] = json.RawMessage(`{"error": "Item not found"}`)
+			}
+		}
+		json.NewEncoder(w).Encode(result)
+	}))
+	t.Cleanup(server.Close)
+	adapter := &TodoistAdapter{endpointURL: server.URL, httpClient: server.Client(), authToken: "token"}
+
+	tasks := []adapters.Task{{ID: "1"}, {ID: "2"}}
+	actions := []adapters.TaskAction{
+		{Task: &tasks[0], Action: adapters.ActionComplete},
+		{Task: &tasks[1], Action: adapters.ActionDelete},
+	}
+	err := adapter.UpdateTasks(&actions)
+	var partial *adapters.PartialError
+	if !errors.As(err, &partial) {
+		t.Fatalf("got error %v, want a partial error", err)
+	}
+	if !slices.Equal(partial.Failed, []string{"2"}) {
+		t.Errorf("got failed tasks %v, want [2]", partial.Failed)
+	}
+	if adapters.KindOf(err) != adapters.ErrorAPI {
+		t.Errorf("got kind %v, want api", adapters.KindOf(err))
+	}
+}
+
+func TestApplyPlanChecksCommands(t *testing.T) {
+	tasks := []adapters.Task{
+		{ID: "1", Content: "Renew passport", Tags: []string{"next"}},
+		{ID: "2", Content: "Call the plumber"},
+	}
+	actions := []adapters.TaskAction{
+		{Task: &tasks[0], Action: adapters.ActionDefer},
+		{Task: &tasks[1], Action: adapters.ActionRevalidate},
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(commands []SyncResponseItem)
+		valid  bool
+	}{
+		{"as planned", func([]SyncResponseItem) {}, true},
+		{"other task", func(commands []SyncResponseItem) { other := "3"; commands[0].Args.Id = &other }, false},
+		{"other type", func(commands []SyncResponseItem) { commands[0].Type = "item_delete" }, false},
+		{"extra change", func(commands []SyncResponseItem) { content := "Hacked"; commands[0].Args.Content = &content }, false},
+		{"other note", func(commands []SyncResponseItem) { note := "hello"; commands[1].Args.Content = &note }, false},
+		{"note on other task", func(commands []SyncResponseItem) { other := "1"; commands[1].Args.ItemId = &other }, false},
+		{"other labels", func(commands []SyncResponseItem) {
+			labels := []string{"someday_maybe", "urgent"}
+			commands[0].Args.Labels = &labels
+		}, false},
+		{"labels removed", func(commands []SyncResponseItem) { commands[0].Args.Labels = nil }, false},
+	}
+	id, labels := "1", []string{"next"}
+	for _, tt := range tests {
+		adapter, requests := newTestAdapter(t, Item{ID: &id, Labels: &labels})
+		plan, err := adapter.PlanTasks(&actions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var commands []SyncResponseItem
+		for _, step := range plan.Steps {
+			var command SyncResponseItem
+			json.Unmarshal(step.Commands[0], &command)
+			commands = append(commands, command)
+		}
+		tt.tamper(commands)
+		for i := range plan.Steps {
+			plan.Steps[i].Commands[0], _ = json.Marshal(commands[i])
+		}
+
+		err = adapter.ApplyPlan(plan)
+		if tt.valid {
+			if err != nil || len(*requests) != 1 {
+				t.Errorf("%s: got error %v and %d requests, want the plan applied", tt.name, err, len(*requests))
+			}
+			continue
+		}
+		if adapters.KindOf(err) != adapters.ErrorValidation || len(*requests) != 0 {
+			t.Errorf("%s: got error %v and %d requests, want a validation error", tt.name, err, len(*requests))
+		}
+	}
+}